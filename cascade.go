@@ -0,0 +1,308 @@
+package gomu
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hatchify/mod-utils/com"
+	"github.com/hatchify/mod-utils/sort"
+)
+
+// Default cascade settings
+const (
+	defaultCascadeFile    = ".gomu-cascade.json"
+	defaultCascadeTimeout = 60
+	defaultPollInterval   = 30
+)
+
+// Cascade lib statuses
+const (
+	cascadeOpened = "opened"
+	cascadeDone   = "done"
+	cascadeFailed = "failed"
+)
+
+// CascadeState represents persisted progress of a cascading sync
+type CascadeState struct {
+	Branch string                  `json:"branch"`
+	Libs   map[string]*CascadeStep `json:"libs"`
+}
+
+// CascadeStep represents the progress of a single lib within a cascade
+type CascadeStep struct {
+	Level    int    `json:"level"`
+	Status   string `json:"status"`
+	PRNumber int    `json:"pr,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// cascadeLevels groups sorted libs by topological level. Libs in a level only depend on libs in previous levels
+func cascadeLevels(fileHead *sort.FileNode) (levels [][]*sort.FileNode) {
+	depth := make(map[*sort.FileNode]int)
+	for itr := fileHead; itr != nil; itr = itr.Next {
+		level := 0
+		for dep := fileHead; dep != itr; dep = dep.Next {
+			if depth[dep] >= level && itr.File.DependsOn(dep.File) {
+				level = depth[dep] + 1
+			}
+		}
+
+		depth[itr] = level
+		for len(levels) <= level {
+			levels = append(levels, nil)
+		}
+
+		levels[level] = append(levels[level], itr)
+	}
+
+	return
+}
+
+func (mu *MU) cascadeFile() string {
+	if len(mu.Options.CascadeFile) > 0 {
+		return mu.Options.CascadeFile
+	}
+
+	return defaultCascadeFile
+}
+
+// loadCascadeState reads saved progress for the current branch, or returns a new state
+func (mu *MU) loadCascadeState() (state *CascadeState) {
+	state = &CascadeState{Branch: mu.Options.Branch, Libs: make(map[string]*CascadeStep)}
+
+	data, err := ioutil.ReadFile(mu.cascadeFile())
+	if err != nil {
+		// Nothing to resume
+		return
+	}
+
+	var saved CascadeState
+	if err = json.Unmarshal(data, &saved); err != nil || saved.Branch != mu.Options.Branch || saved.Libs == nil {
+		com.Println("Ignoring cascade progress in " + mu.cascadeFile() + " (not resumable for this branch)")
+		return
+	}
+
+	com.Println("Resuming cascade progress from " + mu.cascadeFile() + "...")
+	return &saved
+}
+
+// saveCascadeState persists progress so an interrupted cascade can be resumed
+func (mu *MU) saveCascadeState(state *CascadeState) {
+	data, err := json.MarshalIndent(state, "", "\t")
+	if err == nil {
+		err = ioutil.WriteFile(mu.cascadeFile(), data, 0644)
+	}
+
+	if err != nil {
+		mu.Errors = append(mu.Errors, fmt.Errorf("unable to save cascade progress: %v", err))
+	}
+}
+
+// cascade syncs libs one level at a time, waiting for each level's PRs to merge and tagging merged commits before syncing dependants
+func (mu *MU) cascade(fileHead *sort.FileNode) {
+	if !mu.Options.PullRequest {
+		com.Println("Cascade requires pull requests. Nothing to wait for :(")
+		return
+	}
+
+	for itr := fileHead; itr != nil; itr = itr.Next {
+		if err := itr.File.CheckForge(com.FeaturePullRequests); err != nil {
+			// Changes would be tagged without merging
+			itr.File.Output("Unable to cascade :( " + err.Error())
+			mu.Errors = append(mu.Errors, err)
+			return
+		}
+	}
+
+	state := mu.loadCascadeState()
+	levels := cascadeLevels(fileHead)
+
+	index := 0
+	for level, nodes := range levels {
+		com.Println("\nCascade level", level+1, "/", len(levels))
+
+		var pending []Library
+		for _, node := range nodes {
+			index++
+
+			if closed {
+				// Stop execution and clean up
+				return
+			}
+
+			// Separate output
+			com.Println("")
+			com.Println("(", index, "/", mu.Stats.DepCount, ")", node.File.Path)
+
+			var lib Library
+			lib.File = node.File
+
+			step, ok := state.Libs[lib.File.Path]
+			if !ok {
+				step = &CascadeStep{Level: level}
+				state.Libs[lib.File.Path] = step
+			}
+
+			switch step.Status {
+			case cascadeDone:
+				// Completed in a previous run
+				lib.File.Output("Already synced @ " + step.Version)
+				if len(step.Version) > 0 {
+					lib.File.Version = step.Version
+					lib.File.Tagged = true
+				}
+				continue
+			case cascadeOpened:
+				// Resume waiting on the existing PR
+				lib.File.Output("Waiting on existing PR #" + strconv.Itoa(step.PRNumber))
+				lib.File.PROpened = true
+				lib.File.PRNumber = step.PRNumber
+				pending = append(pending, lib)
+				continue
+			}
+
+			step.Error = ""
+			mu.syncLib(lib, fileHead, false)
+
+			if closed {
+				// Stop execution and clean up
+				return
+			}
+
+			if mu.awaitsMerge(lib, step) {
+				pending = append(pending, lib)
+				continue
+			}
+
+			if !lib.File.IsMergedInto("master") {
+				// Tagging would publish unmerged changes
+				step.Status = cascadeFailed
+				step.Error = "HEAD is not merged into master"
+				mu.saveCascadeState(state)
+				lib.File.Error("Refusing to tag :( " + step.Error)
+				mu.Errors = append(mu.Errors, fmt.Errorf("cascade stopped at %s: %s", lib.File.Path, step.Error))
+				com.Println("\nCascade stopped. Re-run to resume from " + mu.cascadeFile())
+				return
+			}
+
+			// Nothing to merge, tag in place
+			mu.tag(lib)
+			step.Status = cascadeDone
+			step.Version = lib.File.Version
+		}
+
+		mu.saveCascadeState(state)
+
+		for _, lib := range pending {
+			step := state.Libs[lib.File.Path]
+
+			merged, err := mu.waitForMerge(lib)
+			if closed {
+				// Stop execution and clean up
+				return
+			}
+
+			if !merged {
+				step.Status = cascadeFailed
+				if err != nil {
+					step.Error = err.Error()
+				}
+
+				mu.saveCascadeState(state)
+				lib.File.Error("PR #" + strconv.Itoa(lib.File.PRNumber) + " did not merge: " + step.Error)
+				mu.Errors = append(mu.Errors, fmt.Errorf("cascade stopped at %s: %s", lib.File.Path, step.Error))
+				com.Println("\nCascade stopped. Re-run to resume from " + mu.cascadeFile())
+				return
+			}
+
+			lib.File.Output("PR merged! Tagging merged commit...")
+			if !performPull("master", &sort.FileNode{File: lib.File}) {
+				step.Status = cascadeFailed
+				step.Error = "unable to pull merged commit"
+				mu.saveCascadeState(state)
+				mu.Errors = append(mu.Errors, fmt.Errorf("cascade stopped at %s: %s", lib.File.Path, step.Error))
+				return
+			}
+
+			mu.tag(lib)
+			step.Status = cascadeDone
+			step.Version = lib.File.Version
+			mu.saveCascadeState(state)
+		}
+	}
+
+	// Cascade complete, nothing left to resume
+	os.Remove(mu.cascadeFile())
+	com.Println("\nCascade complete!")
+}
+
+// awaitsMerge returns true if the lib has an open PR to wait for, including PRs opened by previous runs
+func (mu *MU) awaitsMerge(lib Library, step *CascadeStep) bool {
+	if !lib.File.PROpened {
+		// PR may have been opened previously, with nothing new to commit
+		if pr, err := lib.File.FindPullRequest(mu.Options.Branch); err == nil && pr != nil {
+			lib.File.PROpened = true
+			lib.File.PRNumber = pr.Number
+		}
+	}
+
+	if !lib.File.PROpened || lib.File.PRNumber == 0 {
+		return false
+	}
+
+	step.Status = cascadeOpened
+	step.PRNumber = lib.File.PRNumber
+	return true
+}
+
+// waitForMerge polls the forge until a lib's PR is merged, closed, fails checks or times out
+func (mu *MU) waitForMerge(lib Library) (merged bool, err error) {
+	timeout := mu.Options.CascadeTimeout
+	if timeout <= 0 {
+		timeout = defaultCascadeTimeout
+	}
+
+	interval := mu.Options.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Minute)
+	lib.File.Output("Waiting for PR #" + strconv.Itoa(lib.File.PRNumber) + " to merge...")
+
+	for !closed {
+		var pr *com.PRResponse
+		if pr, err = lib.File.GetPullRequest(lib.File.PRNumber); err == nil {
+			if pr.Merged {
+				return true, nil
+			}
+
+			if pr.State == "closed" {
+				err = fmt.Errorf("closed without merging")
+				return
+			}
+
+			if checks, _ := lib.File.CheckStatus(pr.Head.SHA); checks == "failure" {
+				err = fmt.Errorf("checks failed")
+				return
+			}
+		} else {
+			lib.File.Debug("Unable to check PR status: " + err.Error())
+		}
+
+		if time.Now().After(deadline) {
+			err = fmt.Errorf("timed out after %d minute(s)", timeout)
+			return
+		}
+
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+
+	err = fmt.Errorf("cancelled")
+	return
+}
@@ -0,0 +1,257 @@
+package gomu
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/hatchify/mod-utils/com"
+	"github.com/hatchify/mod-utils/sort"
+)
+
+func testGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+// testGitIdentity sets a fixed git identity and commit time for the test
+func testGitIdentity(t *testing.T) {
+	for name, value := range map[string]string{
+		"GIT_AUTHOR_NAME":     "gomu",
+		"GIT_AUTHOR_EMAIL":    "gomu@example.com",
+		"GIT_AUTHOR_DATE":     "2021-06-01T12:00:00Z",
+		"GIT_COMMITTER_NAME":  "gomu",
+		"GIT_COMMITTER_EMAIL": "gomu@example.com",
+		"GIT_COMMITTER_DATE":  "2021-06-01T12:00:00Z",
+	} {
+		t.Setenv(name, value)
+	}
+}
+
+// testFiles writes files relative to dir
+func testFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		os.MkdirAll(path.Join(dir, path.Dir(name)), 0755)
+		if err := ioutil.WriteFile(path.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// testLib returns a lib at $root/go/src/github.com/org/name, with a go.sum listing deps (other lib names)
+func testLib(t *testing.T, root, name string, deps ...string) *sort.FileNode {
+	t.Helper()
+
+	var goSum string
+	for _, dep := range deps {
+		goSum += "github.com/org/" + dep + " v1.0.0 h1:abc=\n"
+	}
+
+	dir := path.Join(root, "go", "src", "github.com", "org", name)
+	testFiles(t, dir, map[string]string{
+		"go.mod": "module github.com/org/" + name + "\n\ngo 1.17\n",
+		"go.sum": goSum,
+	})
+
+	return &sort.FileNode{File: &com.FileWrapper{Path: dir}}
+}
+
+// testList links nodes in order
+func testList(nodes ...*sort.FileNode) *sort.FileNode {
+	for i := 1; i < len(nodes); i++ {
+		nodes[i-1].Next = nodes[i]
+		nodes[i].Last = nodes[i-1]
+	}
+
+	return nodes[0]
+}
+
+// testForge serves routes ("METHOD /path?query" -> json body) as the github.com api for the test
+func testForge(t *testing.T, routes map[string]interface{}) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Method + " " + r.URL.Path
+		if len(r.URL.RawQuery) > 0 {
+			route += "?" + r.URL.RawQuery
+		}
+
+		body, ok := routes[route]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		json.NewEncoder(w).Encode(body)
+	}))
+
+	t.Cleanup(func() {
+		server.Close()
+		com.SetAPIURL("github.com", "https://api.github.com")
+		com.SetCredentialProviders(com.EnvCredentials, com.GitCredentials, com.NetrcCredentials, com.GomurcCredentials)
+		com.SetInteractive(true)
+	})
+
+	com.SetAPIURL("github.com", server.URL)
+	com.SetInteractive(false)
+	com.SetCredentialProviders(func(host string) (com.GitAuthObject, error) {
+		return com.GitAuthObject{User: "gomu", Token: "test-token"}, nil
+	})
+}
+
+func TestCascadeLevels(t *testing.T) {
+	root := t.TempDir()
+	a := testLib(t, root, "a")
+	b := testLib(t, root, "b", "a")
+	c := testLib(t, root, "c")
+	d := testLib(t, root, "d", "b", "c")
+	e := testLib(t, root, "e", "a", "c")
+
+	tests := []struct {
+		name  string
+		nodes []*sort.FileNode
+		want  [][]string
+	}{
+		{"independent", []*sort.FileNode{a, c}, [][]string{{"a", "c"}}},
+		{"chain", []*sort.FileNode{a, b, d}, [][]string{{"a"}, {"b"}, {"d"}}},
+		{"fleet", []*sort.FileNode{a, c, b, e, d}, [][]string{{"a", "c"}, {"b", "e"}, {"d"}}},
+		{"dependency outside the run", []*sort.FileNode{b, c, d}, [][]string{{"b", "c"}, {"d"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, node := range test.nodes {
+				node.Next, node.Last = nil, nil
+			}
+
+			var got [][]string
+			for _, level := range cascadeLevels(testList(test.nodes...)) {
+				var names []string
+				for _, node := range level {
+					names = append(names, path.Base(node.File.Path))
+				}
+
+				got = append(got, names)
+			}
+
+			if len(got) != len(test.want) {
+				t.Fatalf("cascadeLevels() = %v, want %v", got, test.want)
+			}
+
+			for i := range got {
+				if strings.Join(got[i], ",") != strings.Join(test.want[i], ",") {
+					t.Errorf("cascadeLevels() = %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadCascadeState(t *testing.T) {
+	saved := `{"branch":"deps","libs":{"a":{"level":0,"status":"done","version":"v1.0.1"},"b":{"level":1,"status":"failed","pr":4,"error":"checks failed"}}}`
+
+	tests := []struct {
+		name     string
+		content  string
+		branch   string
+		wantLibs int
+	}{
+		{"resumes branch", saved, "deps", 2},
+		{"other branch", saved, "feature", 0},
+		{"invalid", "{", "deps", 0},
+		{"no libs", `{"branch":"deps"}`, "deps", 0},
+		{"missing", "", "deps", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu MU
+			mu.Options.Branch = test.branch
+			mu.Options.CascadeFile = path.Join(t.TempDir(), defaultCascadeFile)
+			if len(test.content) > 0 {
+				testFiles(t, path.Dir(mu.Options.CascadeFile), map[string]string{defaultCascadeFile: test.content})
+			}
+
+			state := mu.loadCascadeState()
+			if state.Branch != test.branch || state.Libs == nil || len(state.Libs) != test.wantLibs {
+				t.Errorf("loadCascadeState() = %+v, want %d libs for %s", state, test.wantLibs, test.branch)
+			}
+		})
+	}
+}
+
+func TestCascadeResumesFailedStep(t *testing.T) {
+	testGitIdentity(t)
+	lib := testLib(t, t.TempDir(), "repo")
+	dir := lib.File.Path
+
+	testGit(t, dir, "init", "-q")
+	testGit(t, dir, "add", "-A")
+	testGit(t, dir, "commit", "-q", "-m", "Initial commit")
+	testGit(t, dir, "checkout", "-q", "-B", "master")
+	testGit(t, dir, "checkout", "-q", "-b", "deps")
+	testFiles(t, dir, map[string]string{"lib.go": "package repo\n"})
+	testGit(t, dir, "add", "-A")
+	testGit(t, dir, "commit", "-q", "-m", "gomu: Update Mod Files")
+
+	tests := []struct {
+		name        string
+		prs         []com.PRResponse
+		wantPending bool
+		wantPR      int
+	}{
+		{"open PR without new commits", []com.PRResponse{{Number: 9, Head: com.PRRef{Ref: "deps"}}}, true, 9},
+		{"PR for another branch", []com.PRResponse{{Number: 3, Head: com.PRRef{Ref: "other"}}}, false, 0},
+		{"no PR", nil, false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testForge(t, map[string]interface{}{
+				"GET /repos/org/repo/pulls?state=open&per_page=100&page=1": test.prs,
+			})
+
+			var mu MU
+			mu.Options.Branch = "deps"
+
+			// Re-synced after failing, with nothing new to commit
+			lib.File.PROpened, lib.File.PRNumber = false, 0
+			step := &CascadeStep{Level: 0, Status: cascadeFailed, Error: "checks failed"}
+
+			pending := mu.awaitsMerge(Library{File: lib.File}, step)
+			if pending != test.wantPending || step.PRNumber != test.wantPR {
+				t.Errorf("awaitsMerge() = %v, step %+v, want %v with PR %d", pending, step, test.wantPending, test.wantPR)
+			}
+
+			if pending && step.Status != cascadeOpened {
+				t.Errorf("step status = %s, want %s", step.Status, cascadeOpened)
+			}
+		})
+	}
+
+	// Libs without a PR are only tagged in place once merged
+	if lib.File.IsMergedInto("master") {
+		t.Errorf("IsMergedInto() = true for an unmerged branch")
+	}
+
+	testGit(t, dir, "checkout", "-q", "master")
+	testGit(t, dir, "merge", "-q", "deps")
+	testGit(t, dir, "checkout", "-q", "deps")
+	if !lib.File.IsMergedInto("master") {
+		t.Errorf("IsMergedInto() = false after merging")
+	}
+}
@@ -0,0 +1,108 @@
+package gomu
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+// tagWithNotes tags the lib with release notes of commits since the previous tag, as a changelog annotation and/or forge release
+func (mu *MU) tagWithNotes(lib Library, bump string) (newTag string) {
+	tag := mu.Options.SetVersion
+	if len(tag) == 0 {
+		var err error
+		if tag, err = lib.NextTag(bump, mu.Options.Prerelease); err != nil {
+			lib.File.Output("Unable to increment tag :( " + err.Error())
+			return
+		}
+	}
+
+	var previous string
+	if latest, found := lib.File.LatestVersion(); found {
+		previous = latest.Tag()
+	}
+
+	commits, err := lib.File.CommitsSince(previous)
+	if err != nil {
+		lib.File.Output("Unable to read commits for release notes :(")
+	}
+
+	commits, deps := changelogCommits(commits), mu.changelogDeps(lib, commits)
+
+	message := tag
+	if mu.Options.Changelog {
+		message = com.Changelog(tag, time.Now(), commits, deps)
+		if mu.Options.ChangelogFile {
+			mu.commitChangelog(lib, tag, message)
+		}
+	}
+
+	if newTag = lib.AnnotateTag(tag, message); len(newTag) > 0 && mu.Options.Release {
+		mu.release(lib, newTag, com.ReleaseNotes(commits, deps))
+	}
+
+	return
+}
+
+// commitChangelog prepends a changelog entry to the lib's changelog file, then commits and pushes it before tagging
+func (mu *MU) commitChangelog(lib Library, tag, entry string) {
+	lib.File.Output("Updating " + com.ChangelogFile + "...")
+
+	err := lib.File.PrependChangelog(entry)
+	if err == nil {
+		err = lib.File.Add(com.ChangelogFile)
+	}
+	if err == nil {
+		err = lib.File.Commit("gomu: Update changelog for " + tag)
+	}
+	if err == nil {
+		err = lib.File.Push()
+	}
+
+	if err != nil {
+		lib.File.Output("Failed to commit changelog :( " + err.Error())
+	}
+}
+
+// changelogCommits returns commits to list in a changelog, excluding gomu's own mod file commits
+func changelogCommits(commits []com.Commit) (filtered []com.Commit) {
+	for _, commit := range commits {
+		if !strings.HasPrefix(commit.Subject, "gomu:") {
+			filtered = append(filtered, commit)
+		}
+	}
+
+	return
+}
+
+// changelogDeps returns dependency updates for a changelog: the current sync's updates, then any from earlier gomu commits
+func (mu *MU) changelogDeps(lib Library, commits []com.Commit) (deps []string) {
+	seen := make(map[string]bool)
+	add := func(lines string) {
+		for _, line := range strings.Split(lines, "\n") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, "Updated ") && !strings.HasPrefix(line, "Set ") {
+				continue
+			}
+
+			// Keep the most recent version of each dependency
+			url := strings.Split(strings.Fields(line)[1], "@")[0]
+			if !seen[url] {
+				seen[url] = true
+				deps = append(deps, line)
+			}
+		}
+	}
+
+	_, commitMessage := mu.getCommitDetails(lib)
+	add(commitMessage)
+
+	for _, commit := range commits {
+		if strings.HasPrefix(commit.Subject, "gomu:") {
+			add(commit.Body)
+		}
+	}
+
+	return
+}
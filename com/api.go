@@ -0,0 +1,304 @@
+package com
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ExportedAPI maps exported identifiers (dir/Name, dir/Type.Field, dir/Type.Method) to their signatures.
+// Identifiers of the root package have no dir prefix
+type ExportedAPI map[string]string
+
+// APIDiff represents changes to exported identifiers between two versions
+type APIDiff struct {
+	Removed []string
+	Changed []string
+	Added   []string
+
+	// Methods added to exported interfaces break existing implementations
+	AddedInterfaceMethods []string
+}
+
+// interfaceMethodPrefix marks interface method signatures
+const interfaceMethodPrefix = "interface method "
+
+// ExportedAPIAt returns the exported api of all importable packages at a git ref
+func (file *FileWrapper) ExportedAPIAt(ref string) (api ExportedAPI, err error) {
+	output, err := file.CmdOutput("git", "ls-tree", "-r", "--name-only", ref)
+	if err != nil {
+		return
+	}
+
+	api = make(ExportedAPI)
+	fset := token.NewFileSet()
+	for _, filename := range strings.Split(output, "\n") {
+		if !isAPIFile(filename) {
+			continue
+		}
+
+		var src string
+		if src, err = file.CmdOutput("git", "show", ref+":"+filename); err != nil {
+			return
+		}
+
+		parsed, parseErr := parser.ParseFile(fset, filename, src, 0)
+		if parseErr != nil || parsed.Name.Name == "main" {
+			// Ignore unparsable files and commands
+			continue
+		}
+
+		var prefix string
+		if dir := path.Dir(filename); dir != "." {
+			prefix = dir + "/"
+		}
+
+		for _, decl := range parsed.Decls {
+			addDecl(api, fset, prefix, decl)
+		}
+	}
+
+	return
+}
+
+// isAPIFile returns true for go files that contribute to importable packages
+func isAPIFile(filename string) bool {
+	if !strings.HasSuffix(filename, ".go") || strings.HasSuffix(filename, "_test.go") {
+		return false
+	}
+
+	for _, dir := range strings.Split(path.Dir(filename), "/") {
+		switch {
+		case dir == "vendor", dir == "testdata", dir == "internal":
+			return false
+		case strings.HasPrefix(dir, "_"), strings.HasPrefix(dir, ".") && dir != ".":
+			return false
+		}
+	}
+
+	return true
+}
+
+// addDecl adds exported identifiers of a declaration to the api
+func addDecl(api ExportedAPI, fset *token.FileSet, prefix string, decl ast.Decl) {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		if !decl.Name.IsExported() {
+			return
+		}
+
+		signature := "func" + funcSignature(fset, decl.Type)
+		if decl.Recv == nil || len(decl.Recv.List) == 0 {
+			api[prefix+decl.Name.Name] = signature
+			return
+		}
+
+		receiver := receiverName(decl.Recv.List[0].Type)
+		if ast.IsExported(receiver) {
+			api[prefix+receiver+"."+decl.Name.Name] = signature
+		}
+
+	case *ast.GenDecl:
+		for _, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				if spec.Name.IsExported() {
+					addType(api, fset, prefix+spec.Name.Name, spec)
+				}
+
+			case *ast.ValueSpec:
+				kind := decl.Tok.String()
+				for _, name := range spec.Names {
+					if !name.IsExported() {
+						continue
+					}
+
+					signature := kind
+					if spec.Type != nil {
+						signature += " " + exprString(fset, spec.Type)
+					}
+
+					api[prefix+name.Name] = signature
+				}
+			}
+		}
+	}
+}
+
+// addType adds an exported type, and its exported fields or interface methods, to the api
+func addType(api ExportedAPI, fset *token.FileSet, key string, spec *ast.TypeSpec) {
+	prefix := "type "
+	if spec.Assign.IsValid() {
+		prefix = "type = "
+	}
+
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		api[key] = prefix + "struct"
+		for _, field := range typ.Fields.List {
+			fieldType := exprString(fset, field.Type)
+			if len(field.Names) == 0 {
+				// Embedded field
+				if name := receiverName(field.Type); ast.IsExported(name) {
+					api[key+"."+name] = "embedded " + fieldType
+				}
+				continue
+			}
+
+			for _, name := range field.Names {
+				if name.IsExported() {
+					api[key+"."+name.Name] = "field " + fieldType
+				}
+			}
+		}
+
+	case *ast.InterfaceType:
+		api[key] = prefix + "interface"
+		for _, method := range typ.Methods.List {
+			for _, name := range method.Names {
+				if funcType, ok := method.Type.(*ast.FuncType); ok && name.IsExported() {
+					api[key+"."+name.Name] = interfaceMethodPrefix + funcSignature(fset, funcType)
+				}
+			}
+
+			if len(method.Names) == 0 {
+				// Embedded interface
+				api[key+"."+exprString(fset, method.Type)] = interfaceMethodPrefix + "embedded"
+			}
+		}
+
+	default:
+		api[key] = prefix + exprString(fset, spec.Type)
+	}
+}
+
+// funcSignature returns parameter and result types without names
+func funcSignature(fset *token.FileSet, funcType *ast.FuncType) string {
+	signature := "(" + fieldTypes(fset, funcType.Params) + ")"
+	if results := fieldTypes(fset, funcType.Results); strings.Contains(results, ",") {
+		signature += " (" + results + ")"
+	} else if len(results) > 0 {
+		signature += " " + results
+	}
+
+	return signature
+}
+
+// fieldTypes returns a comma separated type for each field, ignoring names
+func fieldTypes(fset *token.FileSet, fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+
+	var types []string
+	for _, field := range fields.List {
+		fieldType := exprString(fset, field.Type)
+
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+
+		for i := 0; i < count; i++ {
+			types = append(types, fieldType)
+		}
+	}
+
+	return strings.Join(types, ", ")
+}
+
+// receiverName returns the type name of a receiver or embedded field (*pkg.Type[T] -> Type)
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+
+	return ""
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+// CompareAPI returns exported identifiers removed, changed or added between two versions
+func CompareAPI(old, new ExportedAPI) (diff APIDiff) {
+	for key, signature := range old {
+		newSignature, ok := new[key]
+		switch {
+		case !ok:
+			diff.Removed = append(diff.Removed, key)
+		case newSignature != signature:
+			diff.Changed = append(diff.Changed, key+": "+signature+" -> "+newSignature)
+		}
+	}
+
+	for key, signature := range new {
+		if _, ok := old[key]; ok {
+			continue
+		}
+
+		var existed bool
+		if index := strings.LastIndex(key, "."); index >= 0 {
+			_, existed = old[key[:index]]
+		}
+
+		if existed && strings.HasPrefix(signature, interfaceMethodPrefix) {
+			diff.AddedInterfaceMethods = append(diff.AddedInterfaceMethods, key)
+		} else {
+			diff.Added = append(diff.Added, key)
+		}
+	}
+
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Added)
+	sort.Strings(diff.AddedInterfaceMethods)
+	return
+}
+
+// Incompatible returns true if existing users of the api may break
+func (diff APIDiff) Incompatible() bool {
+	return len(diff.Removed) > 0 || len(diff.Changed) > 0 || len(diff.AddedInterfaceMethods) > 0
+}
+
+// Bump returns the minimum bump level for the changes: major if incompatible, minor if added, otherwise patch
+func (diff APIDiff) Bump() string {
+	switch {
+	case diff.Incompatible():
+		return "major"
+	case len(diff.Added) > 0:
+		return "minor"
+	default:
+		return "patch"
+	}
+}
+
+// Format returns a readable list of incompatible changes
+func (diff APIDiff) Format() string {
+	var lines []string
+	for _, key := range diff.Removed {
+		lines = append(lines, "- removed "+key)
+	}
+	for _, change := range diff.Changed {
+		lines = append(lines, "- changed "+change)
+	}
+	for _, key := range diff.AddedInterfaceMethods {
+		lines = append(lines, "- added interface method "+key)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,68 @@
+package com
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareAPI(t *testing.T) {
+	old := ExportedAPI{
+		"Client":           "type struct",
+		"Client.Do":        "func(string) error",
+		"Client.Timeout":   "field int",
+		"New":              "func() *Client",
+		"Store":            "type interface",
+		"Store.Get":        interfaceMethodPrefix + "func(string) []byte",
+		"sort/StringArray": "type []string",
+	}
+
+	tests := []struct {
+		name string
+		new  ExportedAPI
+		want APIDiff
+		bump string
+	}{
+		{"unchanged", old, APIDiff{}, "patch"},
+		{"added", merge(old, ExportedAPI{"Client.Close": "func() error", "Open": "func() *Client"}), APIDiff{Added: []string{"Client.Close", "Open"}}, "minor"},
+		{"added type with methods", merge(old, ExportedAPI{"Cache": "type interface", "Cache.Get": interfaceMethodPrefix + "func() []byte"}), APIDiff{Added: []string{"Cache", "Cache.Get"}}, "minor"},
+		{"added interface method", merge(old, ExportedAPI{"Store.Set": interfaceMethodPrefix + "func(string, []byte)"}), APIDiff{AddedInterfaceMethods: []string{"Store.Set"}}, "major"},
+		{"removed", without(old, "New"), APIDiff{Removed: []string{"New"}}, "major"},
+		{"changed", merge(old, ExportedAPI{"Client.Do": "func(string, int) error"}), APIDiff{Changed: []string{"Client.Do: func(string) error -> func(string, int) error"}}, "major"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff := CompareAPI(old, test.new)
+			if !reflect.DeepEqual(diff, test.want) {
+				t.Errorf("CompareAPI() = %+v, want %+v", diff, test.want)
+			}
+
+			if bump := diff.Bump(); bump != test.bump {
+				t.Errorf("Bump() = %s, want %s", bump, test.bump)
+			}
+
+			if diff.Incompatible() != (test.bump == "major") {
+				t.Errorf("Incompatible() = %v", diff.Incompatible())
+			}
+		})
+	}
+}
+
+func merge(api, changes ExportedAPI) ExportedAPI {
+	merged := ExportedAPI{}
+	for key, signature := range api {
+		merged[key] = signature
+	}
+
+	for key, signature := range changes {
+		merged[key] = signature
+	}
+
+	return merged
+}
+
+func without(api ExportedAPI, key string) ExportedAPI {
+	removed := merge(api, nil)
+	delete(removed, key)
+	return removed
+}
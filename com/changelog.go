@@ -0,0 +1,123 @@
+package com
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// ChangelogFile is the file changelog entries are prepended to
+const ChangelogFile = "CHANGELOG.md"
+
+// changelogHeading is the title of new changelog files
+const changelogHeading = "# Changelog"
+
+// changelogSections orders conventional commit types in changelog entries. Other types are grouped last
+var changelogSections = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"refactor", "Refactoring"},
+	{"docs", "Documentation"},
+}
+
+// Changelog returns a markdown changelog entry for a tag, grouping commits by type and listing dependency updates
+func Changelog(tag string, date time.Time, commits []Commit, deps []string) string {
+	return "## " + tag + " - " + date.Format("2006-01-02") + "\n\n" + ReleaseNotes(commits, deps)
+}
+
+// ReleaseNotes returns markdown sections of commits grouped by type, followed by dependency updates
+func ReleaseNotes(commits []Commit, deps []string) string {
+	sections := make(map[string][]string)
+	for _, commit := range commits {
+		line := "- " + commit.Description
+		if len(commit.Scope) > 0 {
+			line = "- **" + commit.Scope + ":** " + commit.Description
+		}
+		if len(commit.Hash) >= 7 {
+			line += " (" + commit.Hash[:7] + ")"
+		}
+
+		switch {
+		case commit.Breaking:
+			sections["Breaking Changes"] = append(sections["Breaking Changes"], line)
+		case len(sectionTitle(commit.Type)) > 0:
+			sections[sectionTitle(commit.Type)] = append(sections[sectionTitle(commit.Type)], line)
+		default:
+			sections["Other Changes"] = append(sections["Other Changes"], line)
+		}
+	}
+
+	for _, dep := range deps {
+		sections["Dependencies"] = append(sections["Dependencies"], "- "+dep)
+	}
+
+	titles := []string{"Breaking Changes"}
+	for _, section := range changelogSections {
+		titles = append(titles, section.Title)
+	}
+	titles = append(titles, "Dependencies", "Other Changes")
+
+	var blocks []string
+	for _, title := range titles {
+		if lines := sections[title]; len(lines) > 0 {
+			blocks = append(blocks, "### "+title+"\n\n"+strings.Join(lines, "\n")+"\n")
+		}
+	}
+
+	if len(blocks) == 0 {
+		return "No changes.\n"
+	}
+
+	return strings.Join(blocks, "\n")
+}
+
+// sectionTitle returns the changelog section for a commit type, or empty for other changes
+func sectionTitle(commitType string) string {
+	for _, section := range changelogSections {
+		if section.Type == commitType {
+			return section.Title
+		}
+	}
+
+	return ""
+}
+
+// PrependChangelog adds an entry to the top of the changelog file, below its heading
+func (file *FileWrapper) PrependChangelog(entry string) (err error) {
+	filename := path.Join(file.Path, ChangelogFile)
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+
+	existing := strings.TrimSpace(string(content))
+	if len(existing) == 0 {
+		existing = changelogHeading
+	}
+
+	var updated string
+	if strings.HasPrefix(existing, "# ") {
+		// Keep the file heading first
+		heading := existing
+		rest := ""
+		if index := strings.Index(existing, "\n"); index >= 0 {
+			heading, rest = existing[:index], strings.TrimSpace(existing[index+1:])
+		}
+
+		updated = heading + "\n\n" + strings.TrimSpace(entry) + "\n"
+		if len(rest) > 0 {
+			updated += "\n" + rest + "\n"
+		}
+	} else {
+		updated = strings.TrimSpace(entry) + "\n\n" + existing + "\n"
+	}
+
+	return ioutil.WriteFile(filename, []byte(updated), 0644)
+}
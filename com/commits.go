@@ -0,0 +1,114 @@
+package com
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Bump levels, ordered by precedence
+var bumpLevels = map[string]int{
+	"":      0,
+	"patch": 1,
+	"minor": 2,
+	"major": 3,
+}
+
+// conventionalPattern matches "type(scope)!: description"
+var conventionalPattern = regexp.MustCompile(`^([a-zA-Z]+)(\(([^)]*)\))?(!)?:\s*(.*)$`)
+
+// breakingFooterPattern matches a "BREAKING CHANGE: description" footer at the start of a body line
+var breakingFooterPattern = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
+
+// Commit represents a parsed commit message
+type Commit struct {
+	Hash    string
+	Subject string
+	Body    string
+
+	// Conventional commit details. Type is empty for non-conventional commits
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+}
+
+// ParseCommit parses a commit subject and body as a conventional commit if possible
+func ParseCommit(hash, subject, body string) (commit Commit) {
+	commit.Hash = hash
+	commit.Subject = strings.TrimSpace(subject)
+	commit.Body = strings.TrimSpace(body)
+	commit.Description = commit.Subject
+
+	if match := conventionalPattern.FindStringSubmatch(commit.Subject); match != nil {
+		commit.Type = strings.ToLower(match[1])
+		commit.Scope = match[3]
+		commit.Breaking = match[4] == "!"
+		commit.Description = match[5]
+	}
+
+	if breakingFooterPattern.MatchString(commit.Body) {
+		commit.Breaking = true
+	}
+
+	return
+}
+
+// Bump returns the version bump implied by the commit: breaking changes are major, feat is minor, everything else is patch
+func (commit Commit) Bump() string {
+	switch {
+	case commit.Breaking:
+		return "major"
+	case commit.Type == "feat":
+		return "minor"
+	default:
+		// Includes fix and gomu's own mod file updates
+		return "patch"
+	}
+}
+
+// MaxBump returns the larger of two bump levels
+func MaxBump(a, b string) string {
+	if bumpLevels[b] > bumpLevels[a] {
+		return b
+	}
+
+	return a
+}
+
+// InferBump returns the largest bump implied by commits since latest. Breaking changes only require a minor bump before v1.0.0
+func InferBump(latest Version, commits []Commit) (bump string) {
+	for _, commit := range commits {
+		bump = MaxBump(bump, commit.Bump())
+	}
+
+	if bump == "major" && latest.Major == 0 {
+		bump = "minor"
+	}
+
+	return
+}
+
+// CommitsSince returns commits reachable from HEAD but not from ref. Returns all commits if ref is empty
+func (file *FileWrapper) CommitsSince(ref string) (commits []Commit, err error) {
+	revisions := "HEAD"
+	if len(ref) > 0 {
+		revisions = ref + "..HEAD"
+	}
+
+	// Separate fields and records with control characters that won't appear in messages
+	output, err := file.CmdOutput("git", "log", revisions, "--format=%H%x1f%s%x1f%b%x1e")
+	if err != nil {
+		return
+	}
+
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.Split(strings.TrimSpace(record), "\x1f")
+		if len(fields) < 3 {
+			continue
+		}
+
+		commits = append(commits, ParseCommit(fields[0], fields[1], fields[2]))
+	}
+
+	return
+}
@@ -0,0 +1,89 @@
+package com
+
+import "testing"
+
+func TestInferBump(t *testing.T) {
+	tests := []struct {
+		name     string
+		latest   string
+		subjects []string
+		bodies   []string
+		want     string
+	}{
+		{"no commits", "v1.2.3", nil, nil, ""},
+		{"fix", "v1.2.3", []string{"fix: nil check"}, []string{""}, "patch"},
+		{"feat", "v1.2.3", []string{"fix: nil check", "feat: add option"}, []string{"", ""}, "minor"},
+		{"breaking subject", "v1.2.3", []string{"feat!: drop option"}, []string{""}, "major"},
+		{"breaking footer", "v1.2.3", []string{"refactor: rename"}, []string{"BREAKING CHANGE: Foo is now Bar"}, "major"},
+		{"breaking before v1", "v0.4.1", []string{"feat!: drop option"}, []string{""}, "minor"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			latest, ok := ParseVersion(test.latest)
+			if !ok {
+				t.Fatalf("invalid version %s", test.latest)
+			}
+
+			var commits []Commit
+			for i, subject := range test.subjects {
+				commits = append(commits, ParseCommit("", subject, test.bodies[i]))
+			}
+
+			if got := InferBump(latest, commits); got != test.want {
+				t.Errorf("InferBump() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBreakingCommitAfterV1(t *testing.T) {
+	latest, _ := ParseVersion("v1.4.0")
+	bump := InferBump(latest, []Commit{ParseCommit("", "feat(api)!: remove Client.Do", "")})
+	if bump != "major" {
+		t.Fatalf("InferBump() = %q, want major", bump)
+	}
+
+	// Tagging v2.0.0 without a /v2 module path would break dependants
+	if next, err := NextVersion(latest, "github.com/hatchify/example", bump, ""); err == nil {
+		t.Errorf("NextVersion() = %s, want error", next)
+	}
+
+	next, err := NextVersion(latest, "github.com/hatchify/example/v2", bump, "")
+	if err != nil || next.String() != "v2.0.0" {
+		t.Errorf("NextVersion() = %s, %v, want v2.0.0 for a migrated module", next, err)
+	}
+}
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		subject string
+		body    string
+		want    Commit
+	}{
+		{"fix: nil check", "", Commit{Type: "fix", Description: "nil check"}},
+		{"Feat(api): add option", "", Commit{Type: "feat", Scope: "api", Description: "add option"}},
+		{"refactor(sort)!: rename", "", Commit{Type: "refactor", Scope: "sort", Description: "rename", Breaking: true}},
+		{"chore: bump", "BREAKING-CHANGE: drops go 1.13", Commit{Type: "chore", Description: "bump", Breaking: true}},
+		{"feat: new api", "Adds Run.\n\nBREAKING CHANGE: Start is removed", Commit{Type: "feat", Description: "new api", Breaking: true}},
+		{"fix: mention", "no BREAKING CHANGE here", Commit{Type: "fix", Description: "mention"}},
+		{"docs: changelog", "> BREAKING CHANGE: quoted from v1.0.0", Commit{Type: "docs", Description: "changelog"}},
+		{"fix: no footer token", "BREAKING CHANGE without a colon", Commit{Type: "fix", Description: "no footer token"}},
+		{"Update readme", "", Commit{Description: "Update readme"}},
+		{"gomu: Update Mod Files", "Updated github.com/x/y@v1.2.3", Commit{Type: "gomu", Description: "Update Mod Files"}},
+		{"fix(: broken", "", Commit{Description: "fix(: broken"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			got := ParseCommit("abc", "  "+test.subject+"\n", test.body)
+			if got.Hash != "abc" || got.Subject != test.subject || got.Body != test.body {
+				t.Errorf("ParseCommit() = %+v, want hash, subject and body kept", got)
+			}
+
+			if got.Type != test.want.Type || got.Scope != test.want.Scope || got.Description != test.want.Description || got.Breaking != test.want.Breaking {
+				t.Errorf("ParseCommit() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
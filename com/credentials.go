@@ -0,0 +1,237 @@
+package com
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path"
+	"strings"
+)
+
+// DefaultHost is used for credentials when no host is specified
+const DefaultHost = "github.com"
+
+// CredentialProvider returns credentials for a git host, or an error if it has none
+type CredentialProvider func(host string) (GitAuthObject, error)
+
+// credentialProviders are checked in order until one returns credentials for a host
+var credentialProviders = []CredentialProvider{
+	EnvCredentials,
+	GitCredentials,
+	NetrcCredentials,
+	GomurcCredentials,
+}
+
+// SetCredentialProviders replaces the credential provider chain
+func SetCredentialProviders(providers ...CredentialProvider) {
+	credentialProviders = providers
+}
+
+// gomurc represents the credentials file. Top level credentials are used for github.com
+type gomurc struct {
+	GitAuthObject
+
+	Hosts map[string]GitAuthObject `json:"hosts,omitempty"`
+}
+
+// LoadAuthFor returns credentials for host from the first provider in the chain that has them
+func LoadAuthFor(host string) (authObject GitAuthObject, err error) {
+	if len(host) == 0 {
+		host = DefaultHost
+	}
+
+	for _, provider := range credentialProviders {
+		if authObject, err = provider(host); err == nil && len(authObject.Token) > 0 {
+			authObject.Host = host
+			return
+		}
+	}
+
+	authObject = GitAuthObject{Host: host}
+	err = fmt.Errorf("no credentials found for %s", host)
+	return
+}
+
+// hostEnvSuffix converts a host to an env var suffix (gitlab.com -> GITLAB_COM)
+func hostEnvSuffix(host string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+
+		return '_'
+	}, host))
+}
+
+// EnvCredentials reads GOMU_TOKEN_<HOST>, or GOMU_TOKEN and GITHUB_TOKEN (default host only) from the environment.
+// User is read from GOMU_USER_<HOST> or GOMU_USER when set
+func EnvCredentials(host string) (authObject GitAuthObject, err error) {
+	suffix := hostEnvSuffix(host)
+
+	authObject.Token = os.Getenv("GOMU_TOKEN_" + suffix)
+	if len(authObject.Token) == 0 && host == DefaultHost {
+		// Unsuffixed tokens are never sent to other hosts
+		authObject.Token = os.Getenv("GOMU_TOKEN")
+		if len(authObject.Token) == 0 {
+			authObject.Token = os.Getenv("GITHUB_TOKEN")
+		}
+	}
+
+	if authObject.User = os.Getenv("GOMU_USER_" + suffix); len(authObject.User) == 0 {
+		authObject.User = os.Getenv("GOMU_USER")
+	}
+
+	if len(authObject.Token) == 0 {
+		err = fmt.Errorf("no token set in environment for %s", host)
+	}
+
+	return
+}
+
+// GitCredentials asks the configured git credential helper for credentials without prompting
+func GitCredentials(host string) (authObject GitAuthObject, err error) {
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+
+	stdout, err := cmd.Output()
+	if err != nil {
+		err = fmt.Errorf("no git credentials for %s", host)
+		return
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	for scanner.Scan() {
+		comps := strings.SplitN(scanner.Text(), "=", 2)
+		if len(comps) != 2 {
+			continue
+		}
+
+		switch comps[0] {
+		case "username":
+			authObject.User = comps[1]
+		case "password":
+			authObject.Token = comps[1]
+		}
+	}
+
+	if len(authObject.Token) == 0 {
+		err = fmt.Errorf("no git credentials for %s", host)
+	}
+
+	return
+}
+
+// NetrcCredentials reads credentials for host (or api.host) from $NETRC or ~/.netrc
+func NetrcCredentials(host string) (authObject GitAuthObject, err error) {
+	netrcPath := os.Getenv("NETRC")
+	if len(netrcPath) == 0 {
+		var usr *user.User
+		if usr, err = user.Current(); err != nil {
+			return
+		}
+
+		netrcPath = path.Join(usr.HomeDir, ".netrc")
+	}
+
+	data, err := ioutil.ReadFile(netrcPath)
+	if err != nil {
+		return
+	}
+
+	var machine string
+	var found, fallback GitAuthObject
+	fields := strings.Fields(string(data))
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 < len(fields) {
+				i++
+				machine = fields[i]
+			}
+		case "default":
+			machine = "default"
+		case "login", "password":
+			if i+1 >= len(fields) {
+				continue
+			}
+
+			var target *GitAuthObject
+			switch machine {
+			case host, "api." + host:
+				target = &found
+			case "default":
+				target = &fallback
+			default:
+				i++
+				continue
+			}
+
+			if fields[i] == "login" {
+				target.User = fields[i+1]
+			} else {
+				target.Token = fields[i+1]
+			}
+			i++
+		}
+	}
+
+	if authObject = found; len(authObject.Token) == 0 {
+		authObject = fallback
+	}
+
+	if len(authObject.Token) == 0 {
+		err = fmt.Errorf("no netrc credentials for %s", host)
+	}
+
+	return
+}
+
+// GomurcCredentials reads credentials for host from ~/.gomurc
+func GomurcCredentials(host string) (authObject GitAuthObject, err error) {
+	rc, err := loadGomurc()
+	if err != nil {
+		return
+	}
+
+	if hostAuth, ok := rc.Hosts[host]; ok {
+		authObject = hostAuth
+	} else if host == DefaultHost {
+		authObject = rc.GitAuthObject
+	}
+
+	if len(authObject.User) == 0 || len(authObject.Token) == 0 {
+		err = fmt.Errorf("auth object missing credentials")
+	}
+
+	return
+}
+
+func gomurcPath() (filepath string, err error) {
+	usr, err := user.Current()
+	if err != nil {
+		return
+	}
+
+	return path.Join(usr.HomeDir, configName), nil
+}
+
+func loadGomurc() (rc gomurc, err error) {
+	filepath, err := gomurcPath()
+	if err != nil {
+		return
+	}
+
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &rc)
+	return
+}
@@ -0,0 +1,91 @@
+package com
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func TestNetrcCredentials(t *testing.T) {
+	netrc := `machine github.com
+	login octocat
+	password gh-token
+
+machine api.gitlab.com login gl-user password gl-token
+machine example.com password only-token
+default login anonymous password fallback-token
+`
+
+	netrcPath := path.Join(t.TempDir(), ".netrc")
+	if err := ioutil.WriteFile(netrcPath, []byte(netrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NETRC", netrcPath)
+
+	tests := []struct {
+		host  string
+		user  string
+		token string
+	}{
+		{"github.com", "octocat", "gh-token"},
+		{"gitlab.com", "gl-user", "gl-token"},
+		{"example.com", "", "only-token"},
+		{"bitbucket.org", "anonymous", "fallback-token"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			authObject, err := NetrcCredentials(test.host)
+			if err != nil || authObject.User != test.user || authObject.Token != test.token {
+				t.Errorf("NetrcCredentials() = %+v, %v, want %s:%s", authObject, err, test.user, test.token)
+			}
+		})
+	}
+}
+
+func TestNetrcCredentialsMissing(t *testing.T) {
+	netrcPath := path.Join(t.TempDir(), ".netrc")
+	if err := ioutil.WriteFile(netrcPath, []byte("machine github.com login octocat\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NETRC", netrcPath)
+
+	if authObject, err := NetrcCredentials("github.com"); err == nil {
+		t.Errorf("NetrcCredentials() = %+v, want error without a password", authObject)
+	}
+
+	t.Setenv("NETRC", path.Join(t.TempDir(), "missing"))
+	if _, err := NetrcCredentials("github.com"); err == nil {
+		t.Errorf("NetrcCredentials() = nil, want error without a netrc file")
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		host  string
+		user  string
+		token string
+	}{
+		{"host token", map[string]string{"GOMU_TOKEN_GITLAB_COM": "gl-token", "GOMU_USER_GITLAB_COM": "gl-user"}, "gitlab.com", "gl-user", "gl-token"},
+		{"host token over default", map[string]string{"GOMU_TOKEN_GITHUB_COM": "host-token", "GOMU_TOKEN": "default-token"}, "github.com", "", "host-token"},
+		{"default token", map[string]string{"GOMU_TOKEN": "default-token", "GITHUB_TOKEN": "gh-token", "GOMU_USER": "gomu"}, "github.com", "gomu", "default-token"},
+		{"github token", map[string]string{"GITHUB_TOKEN": "gh-token"}, "github.com", "", "gh-token"},
+		{"default token on other host", map[string]string{"GOMU_TOKEN": "default-token"}, "git.example.com", "", ""},
+		{"github token on other host", map[string]string{"GITHUB_TOKEN": "gh-token"}, "gitlab.com", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range []string{"GOMU_TOKEN", "GITHUB_TOKEN", "GOMU_USER", "GOMU_TOKEN_GITHUB_COM", "GOMU_TOKEN_GITLAB_COM", "GOMU_USER_GITLAB_COM"} {
+				t.Setenv(name, test.env[name])
+			}
+
+			authObject, err := EnvCredentials(test.host)
+			if authObject.User != test.user || authObject.Token != test.token || (err == nil) != (len(test.token) > 0) {
+				t.Errorf("EnvCredentials() = %+v, %v, want %s:%s", authObject, err, test.user, test.token)
+			}
+		})
+	}
+}
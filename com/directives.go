@@ -0,0 +1,204 @@
+package com
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// Retract adds a retract directive for a version or version range ([v1.0.0, v1.0.5]) to go.mod, with the rationale as a comment
+func (file *FileWrapper) Retract(versions, rationale string) (err error) {
+	if versions, err = RetractRange(versions); err != nil {
+		return
+	}
+
+	lines, err := file.modFileLines()
+	if err != nil {
+		return
+	}
+
+	directive := []string{""}
+	for _, line := range strings.Split(strings.TrimSpace(rationale), "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			// Comments directly above a retract directive are its rationale
+			directive = append(directive, "// "+line)
+		}
+	}
+	directive = append(directive, "retract "+versions)
+
+	return file.writeModFileLines(append(trimTrailing(lines), directive...))
+}
+
+// RetractRange returns a retracted version or version range ([v1.0.0, v1.0.5]) formatted for go.mod, or an error if it is invalid
+func RetractRange(versions string) (formatted string, err error) {
+	formatted = strings.TrimSpace(versions)
+	bounds := strings.Split(strings.Trim(formatted, "[]"), ",")
+	if len(bounds) > 2 || (len(bounds) == 2) != strings.HasPrefix(formatted, "[") {
+		err = fmt.Errorf("invalid retract range %s", formatted)
+		return
+	}
+
+	for _, bound := range bounds {
+		if _, ok := ParseVersion(strings.TrimSpace(bound)); !ok {
+			err = fmt.Errorf("invalid retract version %s", strings.TrimSpace(bound))
+			return
+		}
+	}
+
+	if len(bounds) == 2 {
+		formatted = "[" + strings.TrimSpace(bounds[0]) + ", " + strings.TrimSpace(bounds[1]) + "]"
+	}
+
+	return
+}
+
+// Deprecate sets the deprecation comment of the module directive in go.mod, replacing any existing deprecation
+func (file *FileWrapper) Deprecate(message string) (err error) {
+	if message = strings.TrimSpace(message); len(message) == 0 {
+		return fmt.Errorf("missing deprecation message")
+	}
+
+	lines, err := file.modFileLines()
+	if err != nil {
+		return
+	}
+
+	var updated []string
+	found := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "// Deprecated:") && !found {
+			// Replaced below
+			continue
+		}
+
+		if fields := strings.Fields(trimmed); !found && len(fields) >= 2 && fields[0] == "module" {
+			updated = append(updated, "// Deprecated: "+strings.Join(strings.Fields(message), " "))
+			found = true
+		}
+
+		updated = append(updated, line)
+	}
+
+	if !found {
+		return fmt.Errorf("no module directive found")
+	}
+
+	return file.writeModFileLines(updated)
+}
+
+func (file *FileWrapper) modFileLines() (lines []string, err error) {
+	modFile, err := ioutil.ReadFile(path.Join(file.Path, "go.mod"))
+	if err != nil {
+		err = fmt.Errorf("no mod file found")
+		return
+	}
+
+	return strings.Split(string(modFile), "\n"), nil
+}
+
+func (file *FileWrapper) writeModFileLines(lines []string) error {
+	content := strings.Join(trimTrailing(lines), "\n") + "\n"
+	return ioutil.WriteFile(path.Join(file.Path, "go.mod"), []byte(content), 0644)
+}
+
+// trimTrailing removes trailing empty lines
+func trimTrailing(lines []string) []string {
+	for len(lines) > 0 && len(strings.TrimSpace(lines[len(lines)-1])) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// modDirective represents a go.mod directive, with its verb first. Block entries are expanded (require ( x v1 ) -> require x v1)
+type modDirective struct {
+	Fields  []string
+	Comment string
+}
+
+// modDirectives returns each directive of go.mod
+func (file *FileWrapper) modDirectives() (directives []modDirective) {
+	lines, err := file.modFileLines()
+	if err != nil {
+		return
+	}
+
+	var block string
+	for _, line := range lines {
+		var comment string
+		if index := strings.Index(line, "//"); index >= 0 {
+			line, comment = line[:index], strings.TrimSpace(line[index+2:])
+		}
+
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(block) > 0 && fields[0] == ")":
+			block = ""
+		case len(block) > 0:
+			directives = append(directives, modDirective{append([]string{block}, fields...), comment})
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+		default:
+			directives = append(directives, modDirective{fields, comment})
+		}
+	}
+
+	return
+}
+
+// Requirement represents a module required in go.mod
+type Requirement struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// Requirements returns the modules required in go.mod
+func (file *FileWrapper) Requirements() (requirements []Requirement) {
+	for _, directive := range file.modDirectives() {
+		if len(directive.Fields) >= 3 && directive.Fields[0] == "require" {
+			requirements = append(requirements, Requirement{
+				Path:     directive.Fields[1],
+				Version:  directive.Fields[2],
+				Indirect: strings.HasPrefix(directive.Comment, "indirect"),
+			})
+		}
+	}
+
+	return
+}
+
+// RequiredVersion returns the version of a module required in go.mod, or empty if not required
+func (file *FileWrapper) RequiredVersion(modulePath string) string {
+	for _, requirement := range file.Requirements() {
+		if requirement.Path == modulePath {
+			return requirement.Version
+		}
+	}
+
+	return ""
+}
+
+// ExcludesVersion returns true if go.mod has an exclude directive for the module version
+func (file *FileWrapper) ExcludesVersion(modulePath, version string) bool {
+	for _, directive := range file.modDirectives() {
+		if fields := directive.Fields; len(fields) >= 3 && fields[0] == "exclude" && fields[1] == modulePath && fields[2] == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolveVersion resolves a module version query (latest, v1, etc) to a version from the lib's module context
+func (file *FileWrapper) ResolveVersion(modulePath, query string) (version string, err error) {
+	if version, err = file.CmdOutput("go", "list", "-m", "-f", "{{.Version}}", modulePath+"@"+query); err == nil && len(version) == 0 {
+		err = fmt.Errorf("unable to resolve %s@%s", modulePath, query)
+	}
+
+	return
+}
@@ -0,0 +1,158 @@
+package com
+
+import (
+	"io/ioutil"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testModFile = `// Deprecated: use github.com/x/z
+module github.com/x/y
+
+go 1.17
+
+require (
+	github.com/x/a v1.2.3
+	github.com/x/b v0.1.0 // indirect
+)
+
+require github.com/x/c v2.0.0+incompatible
+
+exclude github.com/x/a v1.2.2
+`
+
+func testModDir(t *testing.T, modFile string) *FileWrapper {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(path.Join(dir, "go.mod"), []byte(modFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return &FileWrapper{Path: dir}
+}
+
+func readModFile(t *testing.T, file *FileWrapper) string {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path.Join(file.Path, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestModDirectives(t *testing.T) {
+	file := testModDir(t, testModFile)
+
+	want := []modDirective{
+		{[]string{"module", "github.com/x/y"}, ""},
+		{[]string{"go", "1.17"}, ""},
+		{[]string{"require", "github.com/x/a", "v1.2.3"}, ""},
+		{[]string{"require", "github.com/x/b", "v0.1.0"}, "indirect"},
+		{[]string{"require", "github.com/x/c", "v2.0.0+incompatible"}, ""},
+		{[]string{"exclude", "github.com/x/a", "v1.2.2"}, ""},
+	}
+
+	if got := file.modDirectives(); !reflect.DeepEqual(got, want) {
+		t.Errorf("modDirectives() = %v, want %v", got, want)
+	}
+}
+
+func TestRequirements(t *testing.T) {
+	file := testModDir(t, testModFile)
+
+	want := []Requirement{
+		{"github.com/x/a", "v1.2.3", false},
+		{"github.com/x/b", "v0.1.0", true},
+		{"github.com/x/c", "v2.0.0+incompatible", false},
+	}
+
+	if got := file.Requirements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Requirements() = %v, want %v", got, want)
+	}
+
+	if got := file.RequiredVersion("github.com/x/b"); got != "v0.1.0" {
+		t.Errorf("RequiredVersion() = %s, want v0.1.0", got)
+	}
+
+	if got := file.RequiredVersion("github.com/x/y"); got != "" {
+		t.Errorf("RequiredVersion() = %s, want empty for the module itself", got)
+	}
+
+	if !file.ExcludesVersion("github.com/x/a", "v1.2.2") || file.ExcludesVersion("github.com/x/a", "v1.2.3") {
+		t.Errorf("ExcludesVersion() does not match the exclude directive")
+	}
+}
+
+func TestRetract(t *testing.T) {
+	tests := []struct {
+		versions  string
+		rationale string
+		want      string
+		wantErr   bool
+	}{
+		{"v1.0.1", "Broken build", "\n// Broken build\nretract v1.0.1\n", false},
+		{"[v1.0.0,v1.0.5]", "Leaks\nconnections", "\n// Leaks\n// connections\nretract [v1.0.0, v1.0.5]\n", false},
+		{"v1.0.1", "", "\nretract v1.0.1\n", false},
+		{"v1.0", "", "", true},
+		{"v1.0.0,v1.0.5", "", "", true},
+		{"[v1.0.0,v1.0.5,v1.0.6]", "", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.versions, func(t *testing.T) {
+			file := testModDir(t, testModFile+"\n\n")
+			err := file.Retract(test.versions, test.rationale)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Retract() = nil, want error")
+				}
+
+				if got := readModFile(t, file); got != testModFile+"\n\n" {
+					t.Errorf("Retract() changed go.mod on error:\n%s", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := readModFile(t, file); got != testModFile+test.want {
+				t.Errorf("Retract() go.mod =\n%s\nwant\n%s", got, testModFile+test.want)
+			}
+		})
+	}
+}
+
+func TestDeprecate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modFile string
+		message string
+		want    string
+		wantErr bool
+	}{
+		{"replaces existing", testModFile, "use  github.com/x/w\ninstead", strings.Replace(testModFile, "use github.com/x/z", "use github.com/x/w instead", 1), false},
+		{"adds", "module github.com/x/y\n\ngo 1.17\n", "use github.com/x/z", "// Deprecated: use github.com/x/z\nmodule github.com/x/y\n\ngo 1.17\n", false},
+		{"empty message", testModFile, " ", testModFile, true},
+		{"no module", "go 1.17\n", "use github.com/x/z", "go 1.17\n", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := testModDir(t, test.modFile)
+			if err := file.Deprecate(test.message); (err != nil) != test.wantErr {
+				t.Fatalf("Deprecate() = %v, want error %v", err, test.wantErr)
+			}
+
+			if got := readModFile(t, file); got != test.want {
+				t.Errorf("Deprecate() go.mod =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package com
 
 import (
 	"io/ioutil"
+	"os"
 	"path"
 	"strings"
 )
@@ -23,6 +24,7 @@ type FileWrapper struct {
 	Tagged        bool
 	Committed     bool
 	PROpened      bool
+	PRNumber      int
 	BranchCreated bool
 	TestFailed    bool
 }
@@ -88,9 +90,23 @@ func (file *FileWrapper) GetGoURL() string {
 	}
 
 	file.goURL = strings.Trim(components[1], "/")
+
+	// Use the major version suffix of the module path (github.com/x/y/v2)
+	if modulePath := file.ModulePath(); len(modulePath) > 0 {
+		if base, major := SplitMajorPath(modulePath); major > 1 && base == file.goURL {
+			file.goURL = modulePath
+		}
+	}
+
 	return file.goURL
 }
 
+// IsVendored returns true if the lib vendors its dependencies (vendor/modules.txt exists)
+func (file *FileWrapper) IsVendored() bool {
+	_, err := os.Stat(path.Join(file.Path, "vendor", "modules.txt"))
+	return err == nil
+}
+
 // DirectlyImports is used to determine direct dependencies.
 // returns true if file/go.mod contains any dep version
 func (file *FileWrapper) DirectlyImports(dep *FileWrapper) bool {
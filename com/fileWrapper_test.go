@@ -0,0 +1,36 @@
+package com
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestIsVendored(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  bool
+	}{
+		{"not vendored", nil, false},
+		{"vendor directory only", []string{"vendor/github.com/x/a/a.go"}, false},
+		{"modules.txt", []string{"vendor/modules.txt"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := &FileWrapper{Path: t.TempDir()}
+			for _, name := range test.files {
+				os.MkdirAll(path.Join(file.Path, path.Dir(name)), 0755)
+				if err := ioutil.WriteFile(path.Join(file.Path, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := file.IsVendored(); got != test.want {
+				t.Errorf("IsVendored() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,519 @@
+package com
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Supported forges
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+)
+
+// apiURLs maps supported git hosts to their api endpoints
+var apiURLs = map[string]string{
+	"github.com": "https://api.github.com",
+	"gitlab.com": "https://gitlab.com/api/v4",
+}
+
+// forges maps git hosts to their forge. Hosts default to github
+var forges = map[string]string{
+	"gitlab.com": ForgeGitLab,
+}
+
+// Forge features implemented through host apis
+const (
+	FeaturePullRequests = "pull requests"
+	FeatureChecks       = "checks"
+	FeatureSecrets      = "secrets"
+	FeatureReleases     = "releases"
+)
+
+// forgeFeatures lists the features implemented for each forge
+var forgeFeatures = map[string][]string{
+	ForgeGitHub: {FeaturePullRequests, FeatureChecks, FeatureSecrets, FeatureReleases},
+	ForgeGitLab: {FeatureReleases},
+}
+
+// forgeNames are readable names of each forge
+var forgeNames = map[string]string{
+	ForgeGitHub: "GitHub",
+	ForgeGitLab: "GitLab",
+}
+
+// SetAPIURL overrides the api endpoint used for a given git host (enterprise hosts, local fake apis, etc)
+func SetAPIURL(host, apiURL string) {
+	apiURLs[host] = strings.TrimRight(apiURL, "/")
+}
+
+// SetForge sets the forge (github or gitlab) and api endpoint of a self-hosted git host
+func SetForge(host, forge, apiURL string) {
+	forges[host] = forge
+	SetAPIURL(host, apiURL)
+}
+
+// Forge returns the forge of a git host
+func Forge(host string) string {
+	if forge, ok := forges[host]; ok {
+		return forge
+	}
+
+	return ForgeGitHub
+}
+
+// HasAPI returns true if gomu knows the api endpoint for a git host
+func HasAPI(host string) bool {
+	_, ok := apiURLs[host]
+	return ok
+}
+
+// Supports returns true if gomu implements a feature for the forge of a git host
+func Supports(host, feature string) bool {
+	if !HasAPI(host) {
+		return false
+	}
+
+	for _, supported := range forgeFeatures[Forge(host)] {
+		if supported == feature {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckForge returns an error if gomu doesn't implement a feature for the lib's forge
+func (file *FileWrapper) CheckForge(feature string) (err error) {
+	_, _, err = file.forgeRepo(feature)
+	return
+}
+
+// forgeRepo returns the git host and owner/repo path, or an error if the host's forge doesn't support a feature
+func (file *FileWrapper) forgeRepo(feature string) (host, repo string, err error) {
+	host, repo = file.repoComponents()
+	switch {
+	case !HasAPI(host):
+		err = fmt.Errorf("%s currently not supported", host)
+	case !Supports(host, feature):
+		err = fmt.Errorf("%s unsupported on %s", feature, forgeNames[Forge(host)])
+	case len(repo) == 0:
+		err = fmt.Errorf("unable to parse repository from %s", file.GetGoURL())
+	}
+
+	return
+}
+
+// PRMetadata represents optional settings applied to a pull request after it is created
+type PRMetadata struct {
+	Labels    []string
+	Reviewers []string
+	Assignees []string
+
+	AutoMerge   bool
+	MergeMethod string
+}
+
+// IsEmpty returns true if there is no metadata to apply
+func (meta PRMetadata) IsEmpty() bool {
+	return len(meta.Labels) == 0 && len(meta.Reviewers) == 0 && len(meta.Assignees) == 0 && !meta.AutoMerge
+}
+
+type labelsRequest struct {
+	Labels []string `json:"labels"`
+}
+
+type assigneesRequest struct {
+	Assignees []string `json:"assignees"`
+}
+
+type reviewersRequest struct {
+	Reviewers     []string `json:"reviewers,omitempty"`
+	TeamReviewers []string `json:"team_reviewers,omitempty"`
+}
+
+type statusResponse struct {
+	State      string `json:"state"`
+	TotalCount int    `json:"total_count"`
+}
+
+type checkRunsResponse struct {
+	TotalCount int `json:"total_count"`
+	CheckRuns  []struct {
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	} `json:"check_runs"`
+}
+
+type reviewResponse struct {
+	State string `json:"state"`
+	User  PRUser `json:"user"`
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Errors []PRResponseError `json:"errors,omitempty"`
+}
+
+const autoMergeMutation = `mutation($id: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) { clientMutationId }
+}`
+
+// perPage is the page size of paginated list requests
+const perPage = 100
+
+// pageResource returns the resource of a page (starting at 1) of a paginated list
+func pageResource(resource string, page int) string {
+	separator := "?"
+	if strings.Contains(resource, "?") {
+		separator = "&"
+	}
+
+	return resource + separator + "per_page=" + strconv.Itoa(perPage) + "&page=" + strconv.Itoa(page)
+}
+
+// repoComponents splits the go url into git host and owner/repo path
+func (file *FileWrapper) repoComponents() (host, repo string) {
+	comps := strings.Split(file.GetGoURL(), "/")
+	host = comps[0]
+
+	if len(comps) > 2 {
+		repo = strings.Join(comps[1:3], "/")
+	}
+
+	return
+}
+
+// apiRequest sends a json request to the api of the provided host and decodes the json response (if any) into response
+func apiRequest(host, method, resource string, authObject GitAuthObject, request, response interface{}) (status int, err error) {
+	apiURL, ok := apiURLs[host]
+	if !ok {
+		err = fmt.Errorf("%s currently not supported", host)
+		return
+	}
+
+	var u *url.URL
+	if u, err = url.ParseRequestURI(apiURL + resource); err != nil {
+		err = fmt.Errorf("Unable to parse url %s", apiURL+resource)
+		return
+	}
+
+	var body io.Reader
+	if request != nil {
+		var data []byte
+		if data, err = json.Marshal(request); err != nil {
+			err = fmt.Errorf("Unable to parse request params")
+			return
+		}
+
+		body = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return
+	}
+
+	if Forge(host) == ForgeGitLab {
+		req.Header.Add("Authorization", "Bearer "+authObject.Token)
+	} else {
+		req.Header.Add("Authorization", "token "+authObject.Token)
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/json")
+
+	// Execute Request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	var data []byte
+	if data, err = ioutil.ReadAll(resp.Body); err != nil {
+		return
+	}
+
+	status = resp.StatusCode
+	if response != nil && len(data) > 0 {
+		// Error responses are decoded as well, so callers may inspect api error messages
+		if decodeErr := json.Unmarshal(data, response); decodeErr != nil && status < 300 {
+			err = fmt.Errorf("Unable to parse response from %s", u.Path)
+		}
+	}
+
+	if status >= 300 {
+		err = fmt.Errorf("Http error %d", status)
+	}
+
+	return
+}
+
+// authorizedRequest makes an api request with credentials for host. Rejected credentials are replaced at most once per host
+func authorizedRequest(host, method, resource string, request, response interface{}) (status int, err error) {
+	authObject, err := getAuthFor(host)
+	if err != nil {
+		err = fmt.Errorf("needs %s credentials", host)
+		return
+	}
+
+	if status, err = apiRequest(host, method, resource, authObject, request, response); status != 401 {
+		return
+	}
+
+	if authObject, err = reauthenticate(authObject); err != nil {
+		return
+	}
+
+	return apiRequest(host, method, resource, authObject, request, response)
+}
+
+// ApplyPRMetadata sets labels, reviewers, assignees and auto-merge on a newly created pull request
+func (file *FileWrapper) ApplyPRMetadata(pr *PRResponse, meta PRMetadata) (err error) {
+	if pr == nil || pr.Number == 0 {
+		return fmt.Errorf("no pull request to update")
+	}
+
+	host, repo, err := file.forgeRepo(FeaturePullRequests)
+	if err != nil {
+		return
+	}
+
+	issue := "/repos/" + repo + "/issues/" + strconv.Itoa(pr.Number)
+	pull := "/repos/" + repo + "/pulls/" + strconv.Itoa(pr.Number)
+
+	var errs []string
+
+	if len(meta.Labels) > 0 {
+		if _, err = authorizedRequest(host, "POST", issue+"/labels", &labelsRequest{meta.Labels}, nil); err != nil {
+			errs = append(errs, "labels: "+err.Error())
+		} else {
+			file.Debug("Labeled PR " + strings.Join(meta.Labels, ", "))
+		}
+	}
+
+	if len(meta.Assignees) > 0 {
+		if _, err = authorizedRequest(host, "POST", issue+"/assignees", &assigneesRequest{meta.Assignees}, nil); err != nil {
+			errs = append(errs, "assignees: "+err.Error())
+		} else {
+			file.Debug("Assigned PR to " + strings.Join(meta.Assignees, ", "))
+		}
+	}
+
+	if len(meta.Reviewers) > 0 {
+		var request reviewersRequest
+		for _, reviewer := range meta.Reviewers {
+			reviewer = strings.TrimPrefix(reviewer, "@")
+			if comps := strings.Split(reviewer, "/"); len(comps) == 2 {
+				// Teams are provided as org/team-slug
+				request.TeamReviewers = append(request.TeamReviewers, comps[1])
+			} else {
+				request.Reviewers = append(request.Reviewers, reviewer)
+			}
+		}
+
+		if _, err = authorizedRequest(host, "POST", pull+"/requested_reviewers", &request, nil); err != nil {
+			errs = append(errs, "reviewers: "+err.Error())
+		} else {
+			file.Debug("Requested review from " + strings.Join(meta.Reviewers, ", "))
+		}
+	}
+
+	if meta.AutoMerge {
+		if err = file.enableAutoMerge(host, pr.NodeID, meta.MergeMethod); err != nil {
+			errs = append(errs, "auto-merge: "+err.Error())
+		} else {
+			file.Debug("Enabled auto-merge")
+		}
+	}
+
+	err = nil
+	if len(errs) > 0 {
+		err = fmt.Errorf("Unable to set PR metadata (%s)", strings.Join(errs, "; "))
+	}
+
+	return
+}
+
+// enableAutoMerge enables auto-merge for a pull request node using the graphql api
+func (file *FileWrapper) enableAutoMerge(host, nodeID, mergeMethod string) (err error) {
+	if len(nodeID) == 0 {
+		return fmt.Errorf("missing pull request node id")
+	}
+
+	if len(mergeMethod) == 0 {
+		mergeMethod = "MERGE"
+	}
+
+	request := &graphQLRequest{
+		Query: autoMergeMutation,
+		Variables: map[string]interface{}{
+			"id":     nodeID,
+			"method": strings.ToUpper(mergeMethod),
+		},
+	}
+
+	var response graphQLResponse
+	if _, err = authorizedRequest(host, "POST", "/graphql", request, &response); err != nil {
+		return
+	}
+
+	if len(response.Errors) > 0 {
+		// GraphQL reports errors with a 200 status
+		err = fmt.Errorf("%s", response.Errors[0].Message)
+	}
+
+	return
+}
+
+// GetPullRequest returns the current state of a pull request by number
+func (file *FileWrapper) GetPullRequest(number int) (pr *PRResponse, err error) {
+	host, repo, err := file.forgeRepo(FeaturePullRequests)
+	if err != nil {
+		return
+	}
+
+	pr = &PRResponse{}
+	pr.HTTPStatus, err = authorizedRequest(host, "GET", "/repos/"+repo+"/pulls/"+strconv.Itoa(number), nil, pr)
+	return
+}
+
+// FindPullRequest returns the open pull request with the provided head branch, or nil if none exists
+func (file *FileWrapper) FindPullRequest(branch string) (pr *PRResponse, err error) {
+	prs, err := file.ListPullRequests("open")
+	if err != nil {
+		return
+	}
+
+	for i := range prs {
+		if prs[i].Head.Ref == branch {
+			pr = &prs[i]
+			return
+		}
+	}
+
+	return
+}
+
+// ListPullRequests returns pull requests for the repository in the provided state (open, closed or all)
+func (file *FileWrapper) ListPullRequests(state string) (prs []PRResponse, err error) {
+	host, repo, err := file.forgeRepo(FeaturePullRequests)
+	if err != nil {
+		return
+	}
+
+	query := url.Values{}
+	query.Set("state", state)
+
+	for page := 1; ; page++ {
+		var pagePRs []PRResponse
+		if _, err = authorizedRequest(host, "GET", pageResource("/repos/"+repo+"/pulls?"+query.Encode(), page), nil, &pagePRs); err != nil {
+			return
+		}
+
+		prs = append(prs, pagePRs...)
+		if len(pagePRs) < perPage {
+			return
+		}
+	}
+}
+
+// CheckStatus returns the combined ci status of a commit: success, pending or failure. Returns empty if no checks are configured
+func (file *FileWrapper) CheckStatus(ref string) (state string, err error) {
+	host, repo, err := file.forgeRepo(FeatureChecks)
+	if err != nil {
+		return
+	}
+
+	var status statusResponse
+	if _, err = authorizedRequest(host, "GET", "/repos/"+repo+"/commits/"+ref+"/status", nil, &status); err != nil {
+		return
+	}
+
+	var checks checkRunsResponse
+	if _, err = authorizedRequest(host, "GET", "/repos/"+repo+"/commits/"+ref+"/check-runs", nil, &checks); err != nil {
+		return
+	}
+
+	if status.TotalCount > 0 {
+		switch status.State {
+		case "failure", "error":
+			return "failure", nil
+		default:
+			state = status.State
+		}
+	}
+
+	for _, run := range checks.CheckRuns {
+		if run.Status != "completed" {
+			state = "pending"
+			continue
+		}
+
+		switch run.Conclusion {
+		case "success", "neutral", "skipped":
+			if len(state) == 0 {
+				state = "success"
+			}
+		default:
+			return "failure", nil
+		}
+	}
+
+	return
+}
+
+// ReviewStatus returns the aggregate review state of a pull request: approved, changes requested or pending
+func (file *FileWrapper) ReviewStatus(number int) (state string, err error) {
+	host, repo, err := file.forgeRepo(FeaturePullRequests)
+	if err != nil {
+		return
+	}
+
+	var reviews []reviewResponse
+	for page := 1; ; page++ {
+		var pageReviews []reviewResponse
+		if _, err = authorizedRequest(host, "GET", pageResource("/repos/"+repo+"/pulls/"+strconv.Itoa(number)+"/reviews", page), nil, &pageReviews); err != nil {
+			return
+		}
+
+		reviews = append(reviews, pageReviews...)
+		if len(pageReviews) < perPage {
+			break
+		}
+	}
+
+	// Only the latest approval or change request counts for each reviewer. Reviews are listed oldest first
+	latest := make(map[string]string)
+	for _, review := range reviews {
+		switch review.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[review.User.Login] = review.State
+		}
+	}
+
+	state = "pending"
+	for _, reviewState := range latest {
+		switch reviewState {
+		case "CHANGES_REQUESTED":
+			return "changes requested", nil
+		case "APPROVED":
+			state = "approved"
+		}
+	}
+
+	return
+}
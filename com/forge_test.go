@@ -0,0 +1,339 @@
+package com
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeResponse is the status and json body returned by a fake api route
+type fakeResponse struct {
+	Status int
+	Body   interface{}
+}
+
+// fakeAPI serves canned responses for "METHOD /path?query" routes and records request bodies by route
+type fakeAPI struct {
+	lock     sync.Mutex
+	requests map[string]string
+}
+
+// request returns the body received for a route, and whether the route was requested
+func (api *fakeAPI) request(route string) (body string, ok bool) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	body, ok = api.requests[route]
+	return
+}
+
+// testAPI serves routes as the api of host with fixed credentials, restoring the previous api and credential providers when the test ends
+func testAPI(t *testing.T, host string, routes map[string]fakeResponse) *fakeAPI {
+	t.Helper()
+
+	authorization := "token test-token"
+	if Forge(host) == ForgeGitLab {
+		authorization = "Bearer test-token"
+	}
+
+	api := &fakeAPI{requests: make(map[string]string)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Method + " " + r.URL.EscapedPath()
+		if len(r.URL.RawQuery) > 0 {
+			route += "?" + r.URL.RawQuery
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		api.lock.Lock()
+		api.requests[route] = string(body)
+		api.lock.Unlock()
+
+		if r.Header.Get("Authorization") != authorization {
+			t.Errorf("%s: Authorization = %q, want %q", route, r.Header.Get("Authorization"), authorization)
+		}
+
+		response, ok := routes[route]
+		if !ok {
+			t.Errorf("unexpected request %s", route)
+			response = fakeResponse{Status: http.StatusNotFound, Body: map[string]string{"message": "Not Found"}}
+		}
+
+		if response.Status == 0 {
+			response.Status = http.StatusOK
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(response.Status)
+		if response.Body != nil {
+			json.NewEncoder(w).Encode(response.Body)
+		}
+	}))
+
+	previousURL, hadURL := apiURLs[host]
+	previousProviders := credentialProviders
+	t.Cleanup(func() {
+		server.Close()
+		credentialProviders = previousProviders
+		SetInteractive(true)
+		delete(sessionAuth, host)
+		delete(reauthenticated, host)
+		if hadURL {
+			apiURLs[host] = previousURL
+		} else {
+			delete(apiURLs, host)
+		}
+	})
+
+	SetAPIURL(host, server.URL)
+	SetInteractive(false)
+	SetCredentialProviders(func(host string) (GitAuthObject, error) {
+		return GitAuthObject{User: "gomu", Token: "test-token"}, nil
+	})
+
+	return api
+}
+
+func TestApplyPRMetadata(t *testing.T) {
+	api := testAPI(t, "github.com", map[string]fakeResponse{
+		"POST /repos/org/repo/issues/5/labels":             {Body: []interface{}{}},
+		"POST /repos/org/repo/issues/5/assignees":          {Status: 201},
+		"POST /repos/org/repo/pulls/5/requested_reviewers": {Status: 422, Body: PRResponse{Errors: []PRResponseError{{Message: "not a collaborator"}}}},
+		"POST /graphql": {Body: graphQLResponse{}},
+	})
+	file := &FileWrapper{goURL: "github.com/org/repo"}
+
+	meta := PRMetadata{
+		Labels:      []string{"deps"},
+		Reviewers:   []string{"@octocat", "org/core"},
+		Assignees:   []string{"gomu"},
+		AutoMerge:   true,
+		MergeMethod: "squash",
+	}
+
+	// Failures are reported together after every setting is attempted
+	err := file.ApplyPRMetadata(&PRResponse{Number: 5, NodeID: "PR_1"}, meta)
+	if err == nil || !strings.Contains(err.Error(), "reviewers: Http error 422") || strings.Contains(err.Error(), "labels") {
+		t.Errorf("ApplyPRMetadata() = %v, want only the reviewers error", err)
+	}
+
+	tests := []struct {
+		route string
+		want  string
+	}{
+		{"POST /repos/org/repo/issues/5/labels", `{"labels":["deps"]}`},
+		{"POST /repos/org/repo/issues/5/assignees", `{"assignees":["gomu"]}`},
+		{"POST /repos/org/repo/pulls/5/requested_reviewers", `{"reviewers":["octocat"],"team_reviewers":["core"]}`},
+	}
+
+	for _, test := range tests {
+		if body, _ := api.request(test.route); body != test.want {
+			t.Errorf("%s body = %s, want %s", test.route, body, test.want)
+		}
+	}
+
+	var request graphQLRequest
+	body, _ := api.request("POST /graphql")
+	json.Unmarshal([]byte(body), &request)
+	if request.Variables["id"] != "PR_1" || request.Variables["method"] != "SQUASH" {
+		t.Errorf("auto-merge variables = %v, want PR_1 and SQUASH", request.Variables)
+	}
+
+	if err = file.ApplyPRMetadata(&PRResponse{}, meta); err == nil {
+		t.Errorf("ApplyPRMetadata() without a PR number = nil, want error")
+	}
+}
+
+func TestEnableAutoMergeError(t *testing.T) {
+	testAPI(t, "github.com", map[string]fakeResponse{
+		"POST /graphql": {Body: graphQLResponse{Errors: []PRResponseError{{Message: "auto merge is not allowed"}}}},
+	})
+	file := &FileWrapper{goURL: "github.com/org/repo"}
+
+	// GraphQL errors are returned with a 200 status
+	err := file.ApplyPRMetadata(&PRResponse{Number: 5, NodeID: "PR_1"}, PRMetadata{AutoMerge: true})
+	if err == nil || !strings.Contains(err.Error(), "auto merge is not allowed") {
+		t.Errorf("ApplyPRMetadata() = %v, want the graphql error", err)
+	}
+}
+
+func TestCheckStatus(t *testing.T) {
+	run := func(status, conclusion string) map[string]string {
+		return map[string]string{"status": status, "conclusion": conclusion}
+	}
+
+	tests := []struct {
+		name   string
+		status statusResponse
+		runs   []map[string]string
+		want   string
+	}{
+		{"no checks", statusResponse{State: "pending"}, nil, ""},
+		{"status success", statusResponse{State: "success", TotalCount: 1}, nil, "success"},
+		{"status failure", statusResponse{State: "error", TotalCount: 1}, []map[string]string{run("completed", "success")}, "failure"},
+		{"runs success", statusResponse{}, []map[string]string{run("completed", "success"), run("completed", "skipped")}, "success"},
+		{"runs pending", statusResponse{}, []map[string]string{run("completed", "success"), run("in_progress", "")}, "pending"},
+		{"runs failure", statusResponse{}, []map[string]string{run("in_progress", ""), run("completed", "timed_out")}, "failure"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testAPI(t, "github.com", map[string]fakeResponse{
+				"GET /repos/org/repo/commits/abc/status":     {Body: test.status},
+				"GET /repos/org/repo/commits/abc/check-runs": {Body: map[string]interface{}{"total_count": len(test.runs), "check_runs": test.runs}},
+			})
+			file := &FileWrapper{goURL: "github.com/org/repo"}
+
+			if state, err := file.CheckStatus("abc"); err != nil || state != test.want {
+				t.Errorf("CheckStatus() = %q, %v, want %q", state, err, test.want)
+			}
+		})
+	}
+}
+
+// testPRs returns count pull requests numbered from first
+func testPRs(first, count int) (prs []PRResponse) {
+	for number := first; number < first+count; number++ {
+		prs = append(prs, PRResponse{Number: number, State: "open", Head: PRRef{Ref: "branch-" + strconv.Itoa(number)}})
+	}
+
+	return
+}
+
+func TestListPullRequests(t *testing.T) {
+	api := testAPI(t, "github.com", map[string]fakeResponse{
+		"GET /repos/org/repo/pulls?state=open&per_page=100&page=1": {Body: testPRs(1, 100)},
+		"GET /repos/org/repo/pulls?state=open&per_page=100&page=2": {Body: testPRs(101, 2)},
+		"GET /repos/org/repo/pulls/7":                              {Body: PRResponse{Number: 7, State: "closed", Merged: true}},
+	})
+	file := &FileWrapper{goURL: "github.com/org/repo"}
+
+	prs, err := file.ListPullRequests("open")
+	if err != nil || len(prs) != 102 {
+		t.Fatalf("ListPullRequests() = %d prs, %v, want 102 from both pages", len(prs), err)
+	}
+
+	pr, err := file.FindPullRequest("branch-102")
+	if err != nil || pr == nil || pr.Number != 102 {
+		t.Errorf("FindPullRequest() = %+v, %v, want #102 from the second page", pr, err)
+	}
+
+	if pr, err = file.FindPullRequest("missing"); err != nil || pr != nil {
+		t.Errorf("FindPullRequest() = %+v, %v, want nil", pr, err)
+	}
+
+	if pr, err = file.GetPullRequest(7); err != nil || !pr.Merged || pr.HTTPStatus != 200 {
+		t.Errorf("GetPullRequest() = %+v, %v, want merged #7", pr, err)
+	}
+
+	if _, ok := api.request("GET /repos/org/repo/pulls?state=open&per_page=100&page=3"); ok {
+		t.Errorf("ListPullRequests() requested a page after a partial page")
+	}
+}
+
+// testReviews returns count reviews in state by distinct users
+func testReviews(state string, count int) (reviews []reviewResponse) {
+	for i := 0; i < count; i++ {
+		reviews = append(reviews, reviewResponse{State: state, User: PRUser{Login: "user-" + strconv.Itoa(i)}})
+	}
+
+	return
+}
+
+func TestReviewStatus(t *testing.T) {
+	changes := reviewResponse{State: "CHANGES_REQUESTED", User: PRUser{Login: "user-0"}}
+	approved := reviewResponse{State: "APPROVED", User: PRUser{Login: "user-0"}}
+	commented := reviewResponse{State: "COMMENTED", User: PRUser{Login: "user-0"}}
+
+	tests := []struct {
+		name  string
+		pages [][]reviewResponse
+		want  string
+	}{
+		{"no reviews", [][]reviewResponse{nil}, "pending"},
+		{"approved", [][]reviewResponse{{approved}}, "approved"},
+		{"comments only", [][]reviewResponse{{commented}}, "pending"},
+		{"changes requested on a later page", [][]reviewResponse{testReviews("APPROVED", 100), {changes}}, "changes requested"},
+		{"approved after changes on a later page", [][]reviewResponse{append([]reviewResponse{changes}, testReviews("COMMENTED", 99)...), {approved, commented}}, "approved"},
+		{"dismissed", [][]reviewResponse{{changes, {State: "DISMISSED", User: PRUser{Login: "user-0"}}}}, "pending"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routes := make(map[string]fakeResponse)
+			for i, page := range test.pages {
+				routes["GET /repos/org/repo/pulls/3/reviews?per_page=100&page="+strconv.Itoa(i+1)] = fakeResponse{Body: page}
+			}
+
+			testAPI(t, "github.com", routes)
+			file := &FileWrapper{goURL: "github.com/org/repo"}
+
+			if state, err := file.ReviewStatus(3); err != nil || state != test.want {
+				t.Errorf("ReviewStatus() = %s, %v, want %s", state, err, test.want)
+			}
+		})
+	}
+}
+
+func TestRejectedCredentials(t *testing.T) {
+	testAPI(t, "github.com", map[string]fakeResponse{
+		"GET /repos/org/repo/pulls/1": {Status: http.StatusUnauthorized, Body: map[string]string{"message": "Bad credentials"}},
+	})
+	file := &FileWrapper{goURL: "github.com/org/repo"}
+
+	// Non-interactive runs fail instead of prompting for new credentials
+	if _, err := file.GetPullRequest(1); err == nil {
+		t.Errorf("GetPullRequest() = nil, want rejected credentials error")
+	}
+}
+
+func TestCheckForge(t *testing.T) {
+	tests := []struct {
+		goURL   string
+		feature string
+		wantErr string
+	}{
+		{"github.com/org/repo", FeaturePullRequests, ""},
+		{"github.com/org/repo/v2", FeatureSecrets, ""},
+		{"gitlab.com/group/repo", FeatureReleases, ""},
+		{"gitlab.com/group/repo", FeaturePullRequests, "pull requests unsupported on GitLab"},
+		{"gitlab.com/group/repo", FeatureChecks, "checks unsupported on GitLab"},
+		{"gitlab.com/group/repo", FeatureSecrets, "secrets unsupported on GitLab"},
+		{"example.com/org/repo", FeatureReleases, "example.com currently not supported"},
+		{"github.com", FeaturePullRequests, "unable to parse repository from github.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.goURL+" "+test.feature, func(t *testing.T) {
+			file := &FileWrapper{goURL: test.goURL}
+
+			err := file.CheckForge(test.feature)
+			if len(test.wantErr) == 0 && err != nil {
+				t.Errorf("CheckForge() = %v, want nil", err)
+			} else if len(test.wantErr) > 0 && (err == nil || err.Error() != test.wantErr) {
+				t.Errorf("CheckForge() = %v, want %s", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnsupportedForgeMakesNoRequests(t *testing.T) {
+	testAPI(t, "gitlab.com", nil)
+	file := &FileWrapper{goURL: "gitlab.com/group/repo"}
+
+	if _, err := file.ListPullRequests("open"); err == nil {
+		t.Errorf("ListPullRequests() = nil, want unsupported error")
+	}
+
+	if _, err := file.CheckStatus("HEAD"); err == nil {
+		t.Errorf("CheckStatus() = nil, want unsupported error")
+	}
+
+	if _, err := file.ListSecrets(); err == nil {
+		t.Errorf("ListSecrets() = nil, want unsupported error")
+	}
+}
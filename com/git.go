@@ -1,13 +1,7 @@
 package com
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
-	"os/user"
 	"path"
 	"strings"
 )
@@ -63,6 +57,17 @@ func (file *FileWrapper) Push() (err error) {
 	return file.RunCmd("git", "push", "-u", "origin")
 }
 
+// IsPushed returns true if HEAD is contained in a remote branch
+func (file *FileWrapper) IsPushed() bool {
+	output, err := file.CmdOutput("git", "branch", "-r", "--contains", "HEAD")
+	return err == nil && len(output) > 0
+}
+
+// IsMergedInto returns true if HEAD is contained in branch
+func (file *FileWrapper) IsMergedInto(branch string) bool {
+	return file.RunCmd("git", "merge-base", "--is-ancestor", "HEAD", branch) == nil
+}
+
 // Stash calls git stash in provided dir
 func (file *FileWrapper) Stash() (err error) {
 	return file.RunCmd("git", "stash")
@@ -91,7 +96,8 @@ func (file *FileWrapper) StashPop() (localChanges bool) {
 func (file *FileWrapper) HasChanges() bool {
 	file.Add(".")
 
-	if file.Commit("revert me") == nil {
+	// Probe commits are never kept, so don't prompt for signing passphrases
+	if file.RunCmd(gitCommand("commit", "--no-gpg-sign", "-m", "revert me")...) == nil {
 		file.RunCmd("git", "reset", "HEAD~1")
 		return true
 	}
@@ -107,9 +113,13 @@ func (file *FileWrapper) Add(filename ...string) (err error) {
 	return file.RunCmd(args...)
 }
 
-// Commit calls git commit with provided message provided in provided dir
+// Commit calls git commit with provided message provided in provided dir. Signs the commit if signing is set
 func (file *FileWrapper) Commit(message string) (err error) {
-	return file.RunCmd("git", "commit", "-m", message)
+	if signing.Sign {
+		return file.RunCmd(gitCommand("commit", "-S", "-m", message)...)
+	}
+
+	return file.RunCmd(gitCommand("commit", "-m", message)...)
 }
 
 // Reset calls git reset with provided args in provieded in provided dir
@@ -125,88 +135,6 @@ func (file *FileWrapper) CurrentBranch() (branch string, err error) {
 	return
 }
 
-// AddSecret will set a secret for the repository
-func (file *FileWrapper) AddSecret(name, secret string) (err error) {
-	var comps = strings.Split(file.GetGoURL(), "/")
-
-	switch comps[0] {
-	case "github.com":
-		// Supported
-	default:
-		// Not Supported
-		err = fmt.Errorf("%s currently not supported for secrets", comps[0])
-		return
-	}
-
-	var apiURL = "https://api." + comps[0]
-	var resource = "/repos/" + strings.Join(comps[1:], "/") + "/actions/secrets/" + name
-
-	var u *url.URL
-	if u, err = url.ParseRequestURI(apiURL); err != nil {
-		err = fmt.Errorf("Unable to parse url %s", apiURL)
-		return
-	}
-
-	// Get auth token
-	authObject, err := getAuth()
-	if err != nil {
-		// Get new creds
-		return fmt.Errorf("needs github credentials for PR")
-	}
-
-	file.Output("Getting encryption key...")
-	id, key, err := authObject.GetPublicKey(file.GetGoURL())
-	if err != nil {
-		file.Output("Error getting public key :(")
-		err = fmt.Errorf("Unable get github public key")
-		return
-	}
-
-	file.Output("Encrypting secret...")
-	encrypted, err := authObject.Encrypt(secret, key)
-	if err != nil {
-		err = fmt.Errorf("Unable to encrypt secret")
-		return
-	}
-
-	post := &secretRequest{Encrypted: encrypted, KeyID: id}
-	data, err := json.Marshal(post)
-	if err != nil {
-		err = fmt.Errorf("Unable to parse secret request")
-		return
-	}
-
-	// Make request
-	u.Path = resource
-	urlStr := u.String()
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", urlStr, bytes.NewBuffer(data))
-	if err != nil {
-		return
-	}
-
-	req.Header.Add("Authorization", "token "+authObject.Token)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
-
-	// Execute Request
-	file.Output("Setting repository secret...")
-	resp, err := client.Do(req)
-	if err != nil {
-		return
-	}
-	resp.Body.Close()
-
-	// Return status
-	if resp.StatusCode == 200 {
-		file.Output("Successfully set repository secret!")
-	} else {
-		err = fmt.Errorf("Http error %d", resp.StatusCode)
-	}
-
-	return
-}
-
 // AddGitWorkflow will set an example yml file for the repo
 func (file *FileWrapper) AddGitWorkflow(exampleYmlPath string) (err error) {
 	// Get source dir and template
@@ -216,7 +144,7 @@ func (file *FileWrapper) AddGitWorkflow(exampleYmlPath string) (err error) {
 
 	// Ignore auto tag for un-tagged libs
 	if ymlTemplate == "auto-tag.yml" {
-		if file.RunCmd("git-tagger", "--action=get") != nil {
+		if _, found := file.LatestVersion(); !found {
 			// No tag set. skip tag
 			err = fmt.Errorf("No tag set... Skipping")
 			return
@@ -253,35 +181,20 @@ func (file *FileWrapper) AddGitWorkflow(exampleYmlPath string) (err error) {
 }
 
 // PullRequest opens a PR for the specified url on the specified branch
-func (file *FileWrapper) PullRequest(title, message, branch, target string) (status *PRResponse, err error) {
+func (file *FileWrapper) PullRequest(title, message, branch, target string, draft bool) (status *PRResponse, err error) {
 	if branch == target {
 		err = fmt.Errorf("Cannot create PR from " + branch + " to " + target)
 		return
 	}
 
-	if err = file.RunCmd("git", "push", "-u", "origin", branch); err != nil {
-		err = fmt.Errorf("Unable to set upstream for branch " + branch + " :( Check repo permissions?")
-		return
-	}
-
-	// Get git host
-	comps := strings.Split(file.GetGoURL(), "/")
-	switch comps[0] {
-	case "github.com":
-		// Supported
-	default:
-		// Not Supported
-		err = fmt.Errorf("%s currently not supported for pull requests", comps[0])
+	// Check the forge before pushing
+	host, repo, err := file.forgeRepo(FeaturePullRequests)
+	if err != nil {
 		return
 	}
 
-	// GitHub api url
-	apiURL := "https://api." + comps[0]
-	resource := "/repos/" + strings.Join(comps[1:], "/") + "/pulls"
-
-	var u *url.URL
-	if u, err = url.ParseRequestURI(apiURL); err != nil {
-		err = fmt.Errorf("Unable to parse url %s", apiURL)
+	if err = file.RunCmd("git", "push", "-u", "origin", branch); err != nil {
+		err = fmt.Errorf("Unable to set upstream for branch " + branch + " :( Check repo permissions?")
 		return
 	}
 
@@ -294,51 +207,13 @@ func (file *FileWrapper) PullRequest(title, message, branch, target string) (sta
 		}
 	}
 
-	post := &prRequest{title, message, branch, target}
-	data, err := json.Marshal(post)
-	if err != nil {
-		err = fmt.Errorf("Unable to parse pull request params")
-		return
-	}
-
-	// Get auth token
-	authObject, err := getAuth()
-	if err != nil {
-		err = fmt.Errorf("needs github credentials for PR")
-		return
-	}
+	post := &prRequest{title, message, branch, target, draft}
 
 	// Make request
-	u.Path = resource
-	urlStr := u.String()
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", urlStr, bytes.NewBuffer(data))
-	if err != nil {
-		return
-	}
-
-	req.Header.Add("Authorization", "token "+authObject.Token)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
-
-	// Execute Request
-	resp, err := client.Do(req)
-	if err != nil {
-		return
-	}
-
-	// Read response
-	var body []byte
-	if body, err = ioutil.ReadAll(resp.Body); err != nil {
-		return
-	}
-	resp.Body.Close()
-
 	payload := &PRResponse{}
-	err = json.Unmarshal(body, payload)
+	payload.HTTPStatus, err = authorizedRequest(host, "POST", "/repos/"+repo+"/pulls", post, payload)
 
 	// Return status
-	payload.HTTPStatus = resp.StatusCode
 	status = payload
 	if status.HTTPStatus >= 300 {
 		err = fmt.Errorf("Http error %d", status.HTTPStatus)
@@ -347,14 +222,5 @@ func (file *FileWrapper) PullRequest(title, message, branch, target string) (sta
 		}
 	}
 
-	if status.HTTPStatus == 401 {
-		// Bad credentials.. clear file
-		usr, _ := user.Current()
-		file.RunCmd("rm", path.Join(usr.HomeDir, configName))
-		file.Output("Bad credentials cleared.")
-		// Try again
-		file.PullRequest(title, message, branch, target)
-	}
-
 	return
 }
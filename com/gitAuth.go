@@ -5,24 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"net/http"
-	"net/url"
 	"os"
-	"os/user"
-	"path"
 	"strings"
+	"sync"
+	"time"
 )
 
 var configName = ".gomurc"
 
-type secretRequest struct {
-	Encrypted string `json:"encrypted_value,omitempty"`
-
-	PublicKey string `json:"key,omitempty"`
-	KeyID     string `json:"key_id,omitempty"`
-
-	HTTPStatus int               `json:"httpStatus,omitempty"`
-	Errors     []PRResponseError `json:"errors,omitempty"`
+type userResponse struct {
+	Login    string `json:"login"`
+	Username string `json:"username"` // gitlab
 }
 
 type prRequest struct {
@@ -30,16 +23,42 @@ type prRequest struct {
 	Body  string `json:"body"`
 	Head  string `json:"head"`
 	Base  string `json:"base"`
+	Draft bool   `json:"draft,omitempty"`
 }
 
 // PRResponse returns the value of github's api response
 type PRResponse struct {
 	HTTPStatus int    `json:"httpStatus,omitempty"`
 	URL        string `json:"html_url,omitempty"`
+	Number     int    `json:"number,omitempty"`
+	NodeID     string `json:"node_id,omitempty"`
+
+	Title          string    `json:"title,omitempty"`
+	State          string    `json:"state,omitempty"`
+	Draft          bool      `json:"draft,omitempty"`
+	Merged         bool      `json:"merged,omitempty"`
+	Mergeable      *bool     `json:"mergeable,omitempty"`
+	MergeableState string    `json:"mergeable_state,omitempty"`
+	MergeCommitSHA string    `json:"merge_commit_sha,omitempty"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+
+	Head PRRef  `json:"head,omitempty"`
+	User PRUser `json:"user,omitempty"`
 
 	Errors []PRResponseError `json:"errors,omitempty"`
 }
 
+// PRRef represents the head or base of a pull request
+type PRRef struct {
+	Ref string `json:"ref,omitempty"`
+	SHA string `json:"sha,omitempty"`
+}
+
+// PRUser represents the author of a pull request
+type PRUser struct {
+	Login string `json:"login,omitempty"`
+}
+
 // PRResponseError returned when PR fails creation
 type PRResponseError struct {
 	Message string `json:"message,omitempty"`
@@ -49,120 +68,69 @@ type PRResponseError struct {
 type GitAuthObject struct {
 	User  string `json:"user"`
 	Token string `json:"token"`
+
+	// Host the credentials belong to. Defaults to github.com
+	Host string `json:"-"`
 }
 
-// LoadAuth will Read credentials from disk
+// LoadAuth will read github.com credentials from the credential provider chain
 func LoadAuth() (authObject GitAuthObject, err error) {
-	usr, err := user.Current()
-	if err != nil {
-		return
-	}
-
-	file, err := ioutil.ReadFile(path.Join(usr.HomeDir, configName))
-	if err != nil {
-		return
-	}
-
-	err = json.Unmarshal(file, &authObject)
-	if err != nil {
-		return
-	}
-
-	if len(authObject.User) == 0 || len(authObject.Token) == 0 {
-		err = fmt.Errorf("auth object missing credentials")
-		return
-	}
-
-	return
+	return LoadAuthFor(DefaultHost)
 }
 
 // Save credentials to disk
 func (authObject *GitAuthObject) Save() (err error) {
-	data, err := json.Marshal(authObject)
+	filepath, err := gomurcPath()
 	if err != nil {
 		return
 	}
 
-	usr, err := user.Current()
-	if err != nil {
-		return
-	}
+	// Keep credentials for other hosts
+	rc, _ := loadGomurc()
 
-	return ioutil.WriteFile(path.Join(usr.HomeDir, configName), data, os.ModePerm)
-}
-
-// Encrypt will salt a secret using sodium lib, and return the encrypted value
-func (authObject *GitAuthObject) Encrypt(secret, key string) (encrypted string, err error) {
-	// TODO: Sodium encrypt https://help.github.com/actions/automating-your-workflow-with-github-actions/creating-and-using-encrypted-secrets
-
-	return
-}
+	host := authObject.Host
+	if len(host) == 0 || host == DefaultHost {
+		rc.User = authObject.User
+		rc.Token = authObject.Token
+	} else {
+		if rc.Hosts == nil {
+			rc.Hosts = make(map[string]GitAuthObject)
+		}
 
-// GetPublicKey will set public key and key id for encrypting secrets
-func (authObject *GitAuthObject) GetPublicKey(goURL string) (id, key string, err error) {
-	// Get git host
-	comps := strings.Split(goURL, "/")
-	switch comps[0] {
-	case "github.com":
-		// Supported
-	default:
-		// Not Supported
-		err = fmt.Errorf("%s currently not supported for pull requests", comps[0])
-		return
+		rc.Hosts[host] = *authObject
 	}
 
-	// GitHub api url
-	apiURL := "https://api." + comps[0]
-	resource := "/repos/" + strings.Join(comps[1:], "/") + "/actions/secrets/public-key"
-
-	u, err := url.ParseRequestURI(apiURL)
+	data, err := json.Marshal(&rc)
 	if err != nil {
-		err = fmt.Errorf("Unable to parse url %s", apiURL)
 		return
 	}
 
-	// Check auth token
-	if err != nil || len(authObject.User) == 0 || len(authObject.Token) == 0 {
-		// Get new creds
-		err = fmt.Errorf("Unable to parse github username and token")
+	if err = ioutil.WriteFile(filepath, data, 0600); err != nil {
 		return
 	}
 
-	// Make request
-	u.Path = resource
-	urlStr := u.String()
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", urlStr, nil)
-	if err != nil {
-		return
-	}
-	req.Header.Add("Authorization", "token "+authObject.Token)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
+	// WriteFile keeps the mode of existing files
+	return os.Chmod(filepath, 0600)
+}
 
-	// Execute Request
-	resp, err := client.Do(req)
-	if err != nil {
-		return
+// Verify checks credentials against the host api and returns the authenticated user's login
+func (authObject *GitAuthObject) Verify() (login string, err error) {
+	host := authObject.Host
+	if len(host) == 0 {
+		host = DefaultHost
 	}
 
-	// Read response
-	var body []byte
-	if body, err = ioutil.ReadAll(resp.Body); err != nil {
+	var response userResponse
+	status, err := apiRequest(host, "GET", "/user", *authObject, nil, &response)
+	if status == 401 {
+		err = fmt.Errorf("%s rejected credentials", host)
+		return
+	} else if err != nil {
 		return
 	}
-	resp.Body.Close()
-	payload := &secretRequest{}
-	err = json.Unmarshal(body, payload)
-
-	// Return status
-	payload.HTTPStatus = resp.StatusCode
-	if payload.HTTPStatus >= 300 {
-		err = fmt.Errorf("Http error %d", payload.HTTPStatus)
-		if len(payload.Errors) > 0 {
-			err = fmt.Errorf("Http Error %d: %s", payload.HTTPStatus, payload.Errors[0].Message)
-			return
-		}
+
+	if login = response.Login; len(login) == 0 {
+		login = response.Username
 	}
 
 	return
@@ -171,7 +139,7 @@ func (authObject *GitAuthObject) GetPublicKey(goURL string) (id, key string, err
 // Setup configures credentials from user input
 // TODO: Move this to CLI? Need to handle differently for plugin...
 func (authObject *GitAuthObject) Setup() (err error) {
-	if logLevel <= SILENT {
+	if logLevel <= SILENT || !IsInteractive() {
 		err = fmt.Errorf("unable to read credentials. auth token or user name not found")
 		return
 	}
@@ -182,7 +150,14 @@ func (authObject *GitAuthObject) Setup() (err error) {
 	var token string
 	reader := bufio.NewReader(os.Stdin)
 
-	fmt.Println("\n( Access Token Instructions @ https://help.github.com/en/github/authenticating-to-github/creating-a-personal-access-token-for-the-command-line )")
+	host := authObject.Host
+	if len(host) == 0 {
+		host = DefaultHost
+	}
+
+	if host == DefaultHost {
+		fmt.Println("\n( Access Token Instructions @ https://help.github.com/en/github/authenticating-to-github/creating-a-personal-access-token-for-the-command-line )")
+	}
 
 	// Parse username and token from command line input
 	for err == nil && (len(authObject.User) == 0 || len(authObject.Token) == 0) {
@@ -196,7 +171,7 @@ func (authObject *GitAuthObject) Setup() (err error) {
 				continue
 			}
 
-			fmt.Print("Enter github username: ")
+			fmt.Print("Enter " + host + " username: ")
 
 		} else if len(token) == 0 {
 			// Get token and save if username set
@@ -204,15 +179,28 @@ func (authObject *GitAuthObject) Setup() (err error) {
 				token = text
 				authObject.User = user
 				authObject.Token = token
+
+				// Don't save credentials the host rejects
+				if _, err = authObject.Verify(); err != nil {
+					fmt.Println("Unable to verify credentials :(\n", err)
+					authObject.Token = ""
+					return
+				}
+
 				if err = authObject.Save(); err != nil {
 					fmt.Println("Error saving credentials :(\n", err)
 				} else {
 					fmt.Println("Saved Credentials!")
 				}
+
+				if loaded, loadErr := LoadAuthFor(host); loadErr == nil && loaded.Token != authObject.Token {
+					// Providers earlier in the chain (env vars, git credential helper, netrc) win on later runs
+					fmt.Println("Warning - " + host + " credentials from the environment, git credential helper or netrc take precedence over " + configName + ". Update them there!")
+				}
 				return
 			}
 
-			fmt.Print("Enter github personal access token: ")
+			fmt.Print("Enter " + host + " personal access token: ")
 		}
 
 		text, err = reader.ReadString('\n')
@@ -224,24 +212,77 @@ func (authObject *GitAuthObject) Setup() (err error) {
 	return
 }
 
-func getAuth() (authObject GitAuthObject, err error) {
-	if authObject, err = LoadAuth(); err == nil {
+// Credentials entered or refreshed during this session, keyed by host
+var (
+	authLock        sync.Mutex
+	sessionAuth     = make(map[string]GitAuthObject)
+	reauthenticated = make(map[string]bool)
+	interactive     = true
+)
+
+// SetInteractive enables or disables credential prompts. Non-interactive runs fail instead of asking
+func SetInteractive(isInteractive bool) {
+	interactive = isInteractive
+}
+
+// IsInteractive returns true if gomu may prompt for input on stdin
+func IsInteractive() bool {
+	if !interactive {
+		return false
+	}
+
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func getAuthFor(host string) (authObject GitAuthObject, err error) {
+	// Prevent concurrent prompts
+	authLock.Lock()
+	defer authLock.Unlock()
+
+	if authObject, ok := sessionAuth[host]; ok {
+		return authObject, nil
+	}
+
+	if authObject, err = LoadAuthFor(host); err == nil {
 		// Auth is valid
 		return
 	}
 
-	// Reset err
-	err = nil
-	err = getNewCredentials(authObject)
+	// Get new creds
+	if err = authObject.Setup(); err != nil || len(authObject.Token) == 0 {
+		err = fmt.Errorf("Unable to parse %s username and token", host)
+		return
+	}
 
+	sessionAuth[host] = authObject
 	return
 }
 
-func getNewCredentials(authObject GitAuthObject) (err error) {
-	// Get new creds
-	if err = authObject.Setup(); err != nil {
-		return fmt.Errorf("Unable to parse github username and token")
+// reauthenticate replaces credentials rejected by the host. Asks at most once per host, and never when non-interactive
+func reauthenticate(rejected GitAuthObject) (authObject GitAuthObject, err error) {
+	authLock.Lock()
+	defer authLock.Unlock()
+
+	host := rejected.Host
+	if current, ok := sessionAuth[host]; ok && current.Token != rejected.Token {
+		// Already replaced by another request
+		return current, nil
+	}
+
+	if reauthenticated[host] {
+		err = fmt.Errorf("%s rejected credentials", host)
+		return
+	}
+	reauthenticated[host] = true
+
+	Println("\n" + host + " rejected credentials. Please re-enter...")
+	authObject = GitAuthObject{Host: host}
+	if err = authObject.Setup(); err != nil || len(authObject.Token) == 0 {
+		err = fmt.Errorf("%s rejected credentials", host)
+		return
 	}
 
+	sessionAuth[host] = authObject
 	return
 }
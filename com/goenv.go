@@ -0,0 +1,102 @@
+package com
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GoEnv represents the environment of spawned go commands. Empty values keep the user's go env
+type GoEnv struct {
+	Private   string // GOPRIVATE
+	Proxy     string // GOPROXY
+	NoProxy   string // GONOPROXY
+	NoSumDB   string // GONOSUMDB
+	Flags     string // GOFLAGS
+	Toolchain string // GOTOOLCHAIN
+}
+
+var goEnv GoEnv
+
+// publicHosts are git hosts served by the public module proxy and checksum database
+var publicHosts = map[string]bool{
+	"github.com": true,
+	"gitlab.com": true,
+}
+
+// SetGoEnv sets the environment of all spawned go commands
+func SetGoEnv(env GoEnv) {
+	goEnv = env
+}
+
+// GetGoEnv returns the environment of spawned go commands
+func GetGoEnv() GoEnv {
+	return goEnv
+}
+
+// GoEnviron returns the environment for a go command, or nil to inherit the current environment
+func GoEnviron() []string {
+	vars := []struct {
+		Key   string
+		Value string
+	}{
+		{"GOPRIVATE", goEnv.Private},
+		{"GOPROXY", goEnv.Proxy},
+		{"GONOPROXY", goEnv.NoProxy},
+		{"GONOSUMDB", goEnv.NoSumDB},
+		{"GOFLAGS", goEnv.Flags},
+		{"GOTOOLCHAIN", goEnv.Toolchain},
+	}
+
+	var environ []string
+	for _, v := range vars {
+		if len(v.Value) > 0 {
+			environ = append(environ, v.Key+"="+v.Value)
+		}
+	}
+
+	if len(environ) == 0 {
+		return nil
+	}
+
+	// Later values take precedence
+	return append(os.Environ(), environ...)
+}
+
+// PrivateHosts returns the self-hosted forge hosts among hosts. Their modules are not in the public proxy or checksum database
+func PrivateHosts(hosts []string) (private []string) {
+	for _, host := range hosts {
+		if HasAPI(host) && !publicHosts[host] {
+			private = append(private, host)
+		}
+	}
+
+	return
+}
+
+// AddPrivate appends module path patterns to GOPRIVATE, keeping the user's go env value if not set
+func (env *GoEnv) AddPrivate(patterns ...string) {
+	if len(patterns) == 0 {
+		return
+	}
+
+	if len(env.Private) == 0 {
+		env.Private = goEnvValue("GOPRIVATE")
+	}
+
+	for _, pattern := range patterns {
+		if !strings.Contains(","+env.Private+",", ","+pattern+",") {
+			env.Private = strings.TrimPrefix(env.Private+","+pattern, ",")
+		}
+	}
+}
+
+// goEnvValue returns a value of the user's go env
+func goEnvValue(key string) string {
+	output, err := exec.Command("go", "env", key).Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(output))
+}
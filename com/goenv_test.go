@@ -0,0 +1,92 @@
+package com
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// environValue returns the value of key in environ. Later values take precedence
+func environValue(environ []string, key string) (value string, found bool) {
+	for _, kv := range environ {
+		if strings.HasPrefix(kv, key+"=") {
+			value, found = strings.TrimPrefix(kv, key+"="), true
+		}
+	}
+
+	return
+}
+
+func TestGoEnviron(t *testing.T) {
+	t.Cleanup(func() { SetGoEnv(GoEnv{}) })
+	t.Setenv("GOPROXY", "https://proxy.example.com")
+	t.Setenv("GOFLAGS", "-mod=vendor")
+
+	SetGoEnv(GoEnv{})
+	if environ := GoEnviron(); environ != nil {
+		t.Errorf("GoEnviron() = %v, want nil to inherit the environment", environ)
+	}
+
+	SetGoEnv(GoEnv{Proxy: "off", Private: "git.example.com", Toolchain: "local"})
+	environ := GoEnviron()
+
+	tests := []struct {
+		key   string
+		want  string
+		found bool
+	}{
+		{"GOPROXY", "off", true},
+		{"GOPRIVATE", "git.example.com", true},
+		{"GOTOOLCHAIN", "local", true},
+		{"GOFLAGS", "-mod=vendor", true},
+		{"GONOSUMDB", "", false},
+	}
+
+	for _, test := range tests {
+		if value, found := environValue(environ, test.key); value != test.want || found != test.found {
+			t.Errorf("GoEnviron() %s = %q, %v, want %q, %v", test.key, value, found, test.want, test.found)
+		}
+	}
+}
+
+func TestAddPrivate(t *testing.T) {
+	// Ignore the user's go env file
+	t.Setenv("GOENV", path.Join(t.TempDir(), "env"))
+
+	tests := []struct {
+		name     string
+		user     string
+		private  string
+		patterns []string
+		want     string
+	}{
+		{"merges user go env", "git.corp.com/*", "", []string{"git.example.com"}, "git.corp.com/*,git.example.com"},
+		{"keeps existing patterns", "git.corp.com/*", "", []string{"git.corp.com/*", "git.example.com"}, "git.corp.com/*,git.example.com"},
+		{"option overrides user go env", "git.corp.com/*", "git.other.com", []string{"git.example.com"}, "git.other.com,git.example.com"},
+		{"no user go env", "", "", []string{"git.example.com", "git.example.com"}, "git.example.com"},
+		{"no patterns", "git.corp.com/*", "", nil, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("GOPRIVATE", test.user)
+
+			env := GoEnv{Private: test.private}
+			env.AddPrivate(test.patterns...)
+			if env.Private != test.want {
+				t.Errorf("AddPrivate() = %q, want %q", env.Private, test.want)
+			}
+		})
+	}
+}
+
+func TestPrivateHosts(t *testing.T) {
+	SetAPIURL("git.example.com", "https://git.example.com/api/v3")
+	t.Cleanup(func() { delete(apiURLs, "git.example.com") })
+
+	hosts := []string{"github.com", "git.example.com", "gitlab.com", "example.com"}
+	if got := PrivateHosts(hosts); !reflect.DeepEqual(got, []string{"git.example.com"}) {
+		t.Errorf("PrivateHosts() = %v, want only the self-hosted forge", got)
+	}
+}
@@ -0,0 +1,128 @@
+package com
+
+import (
+	"strconv"
+	"strings"
+)
+
+// goVersionStages orders pre-release stages of go versions. Language versions (1.21) come first, releases (1.21.0) last
+var goVersionStages = []string{"alpha", "beta", "rc"}
+
+// GoDirective returns the go and toolchain directives of go.mod, or empty if not set
+func (file *FileWrapper) GoDirective() (goVersion, toolchain string) {
+	for _, directive := range file.modDirectives() {
+		if len(directive.Fields) != 2 {
+			continue
+		}
+
+		switch directive.Fields[0] {
+		case "go":
+			goVersion = directive.Fields[1]
+		case "toolchain":
+			toolchain = directive.Fields[1]
+		}
+	}
+
+	return
+}
+
+// SetGoDirective sets the go directive, and the toolchain directive if not empty ("none" removes it)
+func (file *FileWrapper) SetGoDirective(goVersion, toolchain string) error {
+	args := []string{"go", "mod", "edit", "-go=" + goVersion}
+	if len(toolchain) > 0 {
+		args = append(args, "-toolchain="+toolchain)
+	}
+
+	return file.RunCmd(args...)
+}
+
+// DepsRequiringGo returns dependencies in the build list whose go directive is newer than the go version (path@version requires go X)
+func (file *FileWrapper) DepsRequiringGo(goVersion string) (deps []string, err error) {
+	output, err := file.CmdOutput("go", "list", "-mod=mod", "-m", "-f", "{{if not .Main}}{{.Path}}@{{.Version}} {{.GoVersion}}{{end}}", "all")
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && CompareGoVersions(fields[1], goVersion) > 0 {
+			deps = append(deps, fields[0]+" requires go "+fields[1])
+		}
+	}
+
+	return
+}
+
+// IsGoVersion returns true for go versions (1.21, 1.21rc1, 1.21.0), with or without the go prefix
+func IsGoVersion(version string) bool {
+	version = strings.TrimPrefix(version, "go")
+	for _, stage := range goVersionStages {
+		if index := strings.Index(version, stage); index >= 0 {
+			if _, err := strconv.Atoi(version[index+len(stage):]); err != nil {
+				return false
+			}
+
+			version = version[:index]
+		}
+	}
+
+	comps := strings.Split(version, ".")
+	if len(comps) < 2 || len(comps) > 3 {
+		return false
+	}
+
+	for _, comp := range comps {
+		if _, err := strconv.Atoi(comp); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsGoRelease returns true for go release versions (1.21.0), which are valid toolchain names
+func IsGoRelease(version string) bool {
+	version = strings.TrimPrefix(version, "go")
+	return IsGoVersion(version) && strings.Count(version, ".") == 2 && parseGoVersion(version)[2] == len(goVersionStages)+1
+}
+
+// CompareGoVersions returns -1, 0 or 1 if go version a is older, equal or newer than b (1.21 < 1.21rc1 < 1.21.0 < 1.21.1)
+func CompareGoVersions(a, b string) int {
+	partsA, partsB := parseGoVersion(a), parseGoVersion(b)
+	for i := range partsA {
+		if comparison := compareInt(partsA[i], partsB[i]); comparison != 0 {
+			return comparison
+		}
+	}
+
+	return 0
+}
+
+// parseGoVersion returns comparable parts of a go version: major, minor, stage, stage number and patch
+func parseGoVersion(version string) (parts [5]int) {
+	version = strings.TrimPrefix(version, "go")
+
+	// Release unless a pre-release stage is set
+	parts[2] = len(goVersionStages) + 1
+	for i, stage := range goVersionStages {
+		if index := strings.Index(version, stage); index >= 0 {
+			parts[2] = i + 1
+			parts[3], _ = strconv.Atoi(version[index+len(stage):])
+			version = version[:index]
+		}
+	}
+
+	comps := strings.Split(version, ".")
+	parts[0], _ = strconv.Atoi(comps[0])
+	if len(comps) > 1 {
+		parts[1], _ = strconv.Atoi(comps[1])
+	}
+
+	if len(comps) > 2 {
+		parts[4], _ = strconv.Atoi(comps[2])
+	} else if parts[2] > len(goVersionStages) {
+		// Language version
+		parts[2] = 0
+	}
+
+	return
+}
@@ -0,0 +1,62 @@
+package com
+
+import "testing"
+
+func TestIsGoVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+		release bool
+	}{
+		{"1.21", true, false},
+		{"go1.21", true, false},
+		{"1.21.0", true, true},
+		{"go1.21.3", true, true},
+		{"1.21rc1", true, false},
+		{"1.21beta2", true, false},
+		{"1.21.0rc1", true, false},
+		{"1", false, false},
+		{"1.21.0.1", false, false},
+		{"1.x", false, false},
+		{"1.21rc", false, false},
+		{"", false, false},
+	}
+
+	for _, test := range tests {
+		if got := IsGoVersion(test.version); got != test.want {
+			t.Errorf("IsGoVersion(%q) = %v, want %v", test.version, got, test.want)
+		}
+
+		if got := IsGoRelease(test.version); got != test.release {
+			t.Errorf("IsGoRelease(%q) = %v, want %v", test.version, got, test.release)
+		}
+	}
+}
+
+func TestCompareGoVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.21", "1.21", 0},
+		{"go1.21.0", "1.21.0", 0},
+		{"1.20.14", "1.21", -1},
+		{"1.21", "1.21rc1", -1},
+		{"1.21beta1", "1.21rc1", -1},
+		{"1.21rc1", "1.21rc2", -1},
+		{"1.21rc2", "1.21.0", -1},
+		{"1.21.0", "1.21.1", -1},
+		{"1.9", "1.10", -1},
+		{"2.0", "1.99.9", 1},
+	}
+
+	for _, test := range tests {
+		if got := CompareGoVersions(test.a, test.b); got != test.want {
+			t.Errorf("CompareGoVersions(%s, %s) = %d, want %d", test.a, test.b, got, test.want)
+		}
+
+		if got := CompareGoVersions(test.b, test.a); got != -test.want {
+			t.Errorf("CompareGoVersions(%s, %s) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package com
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ModulePath returns the module path declared in go.mod, or empty if not found
+func (file *FileWrapper) ModulePath() string {
+	modFile, err := ioutil.ReadFile(path.Join(file.Path, "go.mod"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(modFile), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`")
+		}
+	}
+
+	return ""
+}
+
+// SetModulePath updates the module directive in go.mod
+func (file *FileWrapper) SetModulePath(modulePath string) (err error) {
+	if err = file.RunCmd("go", "mod", "edit", "-module", modulePath); err != nil {
+		return
+	}
+
+	// Module path may now have a major version suffix
+	file.goURL = ""
+	return
+}
+
+// SplitMajorPath returns the module path without its major version suffix, and the major version (github.com/x/y/v2 -> github.com/x/y, 2).
+// Paths without a suffix are major version 1 or lower
+func SplitMajorPath(modulePath string) (base string, major int) {
+	index := strings.LastIndex(modulePath, "/")
+	if index < 0 {
+		return modulePath, 1
+	}
+
+	suffix := modulePath[index+1:]
+	if len(suffix) < 2 || suffix[0] != 'v' || suffix[1] == '0' {
+		return modulePath, 1
+	}
+
+	major, err := strconv.Atoi(suffix[1:])
+	if err != nil || major < 2 {
+		return modulePath, 1
+	}
+
+	return modulePath[:index], major
+}
+
+// MatchesMajor returns true if a major version can be tagged for the module path. Paths without a suffix allow v0 and v1
+func MatchesMajor(modulePath string, major int) bool {
+	_, pathMajor := SplitMajorPath(modulePath)
+	if pathMajor > 1 {
+		return major == pathMajor
+	}
+
+	return major <= 1
+}
+
+// MajorPath returns the module path for a major version (github.com/x/y, 2 -> github.com/x/y/v2)
+func MajorPath(modulePath string, major int) string {
+	base, _ := SplitMajorPath(modulePath)
+	if major < 2 {
+		return base
+	}
+
+	return base + "/v" + strconv.Itoa(major)
+}
+
+// RewriteImports replaces import paths of renamed modules (old -> new) in all go files of the module.
+// Returns the number of files changed
+func (file *FileWrapper) RewriteImports(renames map[string]string) (changed int, err error) {
+	root := file.AbsPath()
+	err = filepath.Walk(root, func(filename string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if info.IsDir() {
+			name := info.Name()
+			if filename != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+
+			if _, statErr := os.Stat(path.Join(filename, "go.mod")); filename != root && statErr == nil {
+				// Nested modules are separate libs
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !strings.HasSuffix(filename, ".go") {
+			return nil
+		}
+
+		updated, rewriteErr := rewriteFileImports(filename, renames)
+		if updated {
+			changed++
+		}
+
+		return rewriteErr
+	})
+
+	return
+}
+
+// rewriteFileImports replaces renamed import paths in a single go file, preserving formatting
+func rewriteFileImports(filename string, renames map[string]string) (updated bool, err error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+
+	parsed, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.ImportsOnly)
+	if err != nil {
+		// Leave unparsable files untouched
+		return false, nil
+	}
+
+	var output []byte
+	last := 0
+	for _, spec := range parsed.Imports {
+		importPath, unquoteErr := strconv.Unquote(spec.Path.Value)
+		if unquoteErr != nil {
+			continue
+		}
+
+		newPath, ok := renamedImport(importPath, renames)
+		if !ok {
+			continue
+		}
+
+		// Positions are 1-based offsets for a single file set
+		start := int(spec.Path.Pos()) - 1
+		end := int(spec.Path.End()) - 1
+		output = append(output, src[last:start]...)
+		output = append(output, strconv.Quote(newPath)...)
+		last = end
+		updated = true
+	}
+
+	if !updated {
+		return
+	}
+
+	output = append(output, src[last:]...)
+	err = ioutil.WriteFile(filename, output, 0644)
+	return
+}
+
+// renamedImport returns the new import path if the import belongs to a renamed module
+func renamedImport(importPath string, renames map[string]string) (newPath string, ok bool) {
+	for oldModule, newModule := range renames {
+		if importPath == oldModule {
+			return newModule, true
+		}
+
+		if !strings.HasPrefix(importPath, oldModule+"/") {
+			continue
+		}
+
+		element := strings.SplitN(importPath[len(oldModule)+1:], "/", 2)[0]
+		if _, major := SplitMajorPath(oldModule + "/" + element); major > 1 {
+			// Already importing another major version of the module
+			continue
+		}
+
+		return newModule + importPath[len(oldModule):], true
+	}
+
+	return
+}
+
+// RequiresModule returns true if go.mod requires any version of the module path
+func (file *FileWrapper) RequiresModule(modulePath string) bool {
+	modFile, err := ioutil.ReadFile(path.Join(file.Path, "go.mod"))
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(string(modFile), modulePath+" v")
+}
@@ -0,0 +1,191 @@
+package com
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/zip"
+)
+
+// ModuleVersions returns the version tags matching the major version of the module path
+func (file *FileWrapper) ModuleVersions(modulePath string) (versions []Version, err error) {
+	output, err := file.CmdOutput("git", "tag", "--list")
+	if err != nil {
+		return
+	}
+
+	versions = moduleVersions(strings.Split(output, "\n"), modulePath)
+	return
+}
+
+// moduleVersions returns the canonical version tags matching the major version of the module path
+func moduleVersions(tags []string, modulePath string) (versions []Version) {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		version, ok := ParseVersion(tag)
+		if !ok || version.String() != tag {
+			// Ignore non-semver tags and build metadata
+			continue
+		}
+
+		if !MatchesMajor(modulePath, version.Major) {
+			// Tag belongs to another major version of the module
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	return
+}
+
+// latestVersion returns the highest version, preferring releases over pre-releases
+func latestVersion(versions []Version) (latest Version, found bool) {
+	for _, version := range versions {
+		switch {
+		case !found:
+		case latest.IsPrerelease() && !version.IsPrerelease():
+		case version.IsPrerelease() && !latest.IsPrerelease():
+			continue
+		case version.Compare(latest) <= 0:
+			continue
+		}
+
+		latest = version
+		found = true
+	}
+
+	return
+}
+
+// LatestModuleVersion returns the latest version tag matching the major version of the module path
+func (file *FileWrapper) LatestModuleVersion(modulePath string) (latest Version, found bool) {
+	versions, err := file.ModuleVersions(modulePath)
+	if err != nil {
+		return
+	}
+
+	return latestVersion(versions)
+}
+
+// RevisionInfo returns the commit hash and commit time of a revision
+func (file *FileWrapper) RevisionInfo(rev string) (hash string, timestamp time.Time, err error) {
+	if len(rev) == 0 || strings.HasPrefix(rev, "-") {
+		err = fmt.Errorf("invalid revision %q", rev)
+		return
+	}
+
+	output, err := file.CmdOutput("git", "log", "-1", "--format=%H %cI", rev+"^{commit}", "--")
+	if err != nil {
+		return
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		err = fmt.Errorf("unable to parse revision %s: %s", rev, output)
+		return
+	}
+
+	hash = fields[0]
+	timestamp, err = time.Parse(time.RFC3339, fields[1])
+	timestamp = timestamp.UTC()
+	return
+}
+
+// PseudoVersion returns a pseudo-version for a commit, based on the highest version tag reachable from it
+func (file *FileWrapper) PseudoVersion(modulePath, hash string) (pseudo string, err error) {
+	hash, timestamp, err := file.RevisionInfo(hash)
+	if err != nil {
+		return
+	}
+
+	tags, err := file.MergedTags(hash)
+	if err != nil {
+		return
+	}
+
+	// Base on the highest version, including pre-releases
+	var older string
+	var highest Version
+	for _, version := range moduleVersions(tags, modulePath) {
+		if len(older) == 0 || version.Compare(highest) > 0 {
+			highest = version
+			older = version.String()
+		}
+	}
+
+	var major string
+	if _, number := SplitMajorPath(modulePath); number > 1 {
+		major = "v" + strconv.Itoa(number)
+	}
+
+	pseudo = module.PseudoVersion(major, older, timestamp, hash[:12])
+	return
+}
+
+// ResolveRevision returns the canonical version and commit hash for a version tag, pseudo-version, branch or commit
+func (file *FileWrapper) ResolveRevision(modulePath, query string) (version, hash string, err error) {
+	if module.IsPseudoVersion(query) {
+		var rev string
+		if rev, err = module.PseudoVersionRev(query); err != nil {
+			return
+		}
+
+		hash, _, err = file.RevisionInfo(rev)
+		return query, hash, err
+	}
+
+	if parsed, ok := ParseVersion(query); ok && parsed.String() == query {
+		if len(moduleVersions([]string{query}, modulePath)) == 0 {
+			err = fmt.Errorf("version %s does not match module %s", query, modulePath)
+			return
+		}
+
+		hash, _, err = file.RevisionInfo("refs/tags/" + query)
+		return query, hash, err
+	}
+
+	if hash, _, err = file.RevisionInfo(query); err != nil {
+		return
+	}
+
+	// Prefer a version tag pointing at the revision
+	output, err := file.CmdOutput("git", "tag", "--points-at", hash)
+	if err != nil {
+		return
+	}
+
+	if latest, found := latestVersion(moduleVersions(strings.Split(output, "\n"), modulePath)); found {
+		return latest.String(), hash, nil
+	}
+
+	version, err = file.PseudoVersion(modulePath, hash)
+	return
+}
+
+// ModFileAt returns the go.mod file of a commit. Commits without go.mod only declare the module path
+func (file *FileWrapper) ModFileAt(modulePath, hash string) (modFile []byte, err error) {
+	output, err := file.CmdOutput("git", "ls-tree", "--name-only", hash, "go.mod")
+	if err != nil || len(output) == 0 {
+		return []byte("module " + modulePath + "\n"), err
+	}
+
+	// Checksums depend on the exact content
+	return file.CmdBytes("git", "show", hash+":go.mod")
+}
+
+// RepoSubdir returns the path of the lib within its git repo, or empty if the lib is the repo root
+func (file *FileWrapper) RepoSubdir() (subdir string, err error) {
+	subdir, err = file.CmdOutput("git", "rev-parse", "--show-prefix")
+	subdir = strings.TrimSuffix(subdir, "/")
+	return
+}
+
+// ModuleZip writes the module zip file of a commit. The module must be at the repo root
+func (file *FileWrapper) ModuleZip(w io.Writer, modulePath, version, hash string) error {
+	return zip.CreateFromVCS(w, module.Version{Path: modulePath, Version: version}, file.AbsPath(), hash, "")
+}
@@ -0,0 +1,143 @@
+package com
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testRepo returns a git repo with a go.mod for modulePath and a commit at a fixed time
+func testRepo(t *testing.T, modulePath string) *FileWrapper {
+	t.Helper()
+
+	file := testModDir(t, "module "+modulePath+"\n\ngo 1.17\n")
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(name, "gomu")
+	}
+	for _, name := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "gomu@example.com")
+	}
+	for _, name := range []string{"GIT_AUTHOR_DATE", "GIT_COMMITTER_DATE"} {
+		t.Setenv(name, "2021-06-01T12:00:00Z")
+	}
+
+	testGit(t, file, "init", "-q")
+	testCommit(t, file, "lib.go", "package lib\n")
+	return file
+}
+
+func testGit(t *testing.T, file *FileWrapper, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false"}, args...)...)
+	cmd.Dir = file.Path
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+func testCommit(t *testing.T, file *FileWrapper, name, content string) string {
+	t.Helper()
+
+	if err := ioutil.WriteFile(path.Join(file.Path, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testGit(t, file, "add", "-A")
+	testGit(t, file, "commit", "-q", "-m", "Update "+name)
+	return testGit(t, file, "rev-parse", "HEAD")
+}
+
+func TestModuleVersions(t *testing.T) {
+	tags := []string{"v1.0.0", " v1.1.0-rc.1", "v1.2.0+build", "v2.0.0", "v2.1.0", "v01.0.0", "release-1", "", "v0.9.0"}
+
+	tests := []struct {
+		modulePath string
+		want       []string
+	}{
+		{"github.com/x/y", []string{"v1.0.0", "v1.1.0-rc.1", "v0.9.0"}},
+		{"github.com/x/y/v2", []string{"v2.0.0", "v2.1.0"}},
+		{"github.com/x/y/v3", nil},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, version := range moduleVersions(tags, test.modulePath) {
+			got = append(got, version.String())
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("moduleVersions(%s) = %v, want %v", test.modulePath, got, test.want)
+		}
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		want     string
+	}{
+		{"none", nil, ""},
+		{"highest release", []string{"v1.0.0", "v1.2.0", "v1.1.9"}, "v1.2.0"},
+		{"prefers releases", []string{"v1.3.0-rc.1", "v1.2.0", "v1.4.0-beta.2"}, "v1.2.0"},
+		{"prefers releases listed later", []string{"v1.3.0-rc.1", "v1.0.0"}, "v1.0.0"},
+		{"highest pre-release", []string{"v1.3.0-rc.1", "v1.3.0-rc.2", "v1.3.0-beta.5"}, "v1.3.0-rc.2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var versions []Version
+			for _, tag := range test.versions {
+				versions = append(versions, mustParse(t, tag))
+			}
+
+			latest, found := latestVersion(versions)
+			if found != (len(test.want) > 0) || (found && latest.String() != test.want) {
+				t.Errorf("latestVersion() = %s, %v, want %s", latest, found, test.want)
+			}
+		})
+	}
+}
+
+func TestPseudoVersion(t *testing.T) {
+	file := testRepo(t, "github.com/x/y")
+	untagged := testGit(t, file, "rev-parse", "HEAD")
+
+	testCommit(t, file, "lib.go", "package lib\n\nconst Lib = 1\n")
+	testGit(t, file, "tag", "v1.2.0")
+	testGit(t, file, "tag", "v1.3.0-rc.1")
+	testGit(t, file, "tag", "v2.0.0")
+	tagged := testCommit(t, file, "other.go", "package lib\n\nconst Other = 1\n")
+
+	tests := []struct {
+		name       string
+		modulePath string
+		hash       string
+		want       string
+	}{
+		{"untagged", "github.com/x/y", untagged, "v0.0.0-20210601120000-" + untagged[:12]},
+		{"after pre-release", "github.com/x/y", tagged, "v1.3.0-rc.1.0.20210601120000-" + tagged[:12]},
+		{"major path", "github.com/x/y/v2", tagged, "v2.0.1-0.20210601120000-" + tagged[:12]},
+		{"major path without tags", "github.com/x/y/v3", tagged, "v3.0.0-20210601120000-" + tagged[:12]},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pseudo, err := file.PseudoVersion(test.modulePath, test.hash)
+			if err != nil || pseudo != test.want {
+				t.Errorf("PseudoVersion() = %s, %v, want %s", pseudo, err, test.want)
+			}
+		})
+	}
+
+	if _, err := file.PseudoVersion("github.com/x/y", "--all"); err == nil {
+		t.Errorf("PseudoVersion() accepted an option as revision")
+	}
+}
@@ -0,0 +1,102 @@
+package com
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Release represents a forge release for a tag
+type Release struct {
+	Tag        string
+	Name       string
+	Notes      string
+	Prerelease bool
+}
+
+type githubReleaseRequest struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+type githubReleaseResponse struct {
+	HTMLURL string            `json:"html_url"`
+	Errors  []PRResponseError `json:"errors,omitempty"`
+}
+
+type gitlabReleaseRequest struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type gitlabReleaseResponse struct {
+	Message interface{} `json:"message"`
+	Links   struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+// CreateRelease creates a release for a pushed tag on the lib's forge and returns the release url
+func (file *FileWrapper) CreateRelease(release Release) (releaseURL string, err error) {
+	host, repo, err := file.forgeRepo(FeatureReleases)
+	if err != nil {
+		return
+	}
+
+	if len(release.Name) == 0 {
+		release.Name = release.Tag
+	}
+
+	if Forge(host) == ForgeGitLab {
+		return file.createGitLabRelease(host, release)
+	}
+
+	request := &githubReleaseRequest{
+		TagName:    release.Tag,
+		Name:       release.Name,
+		Body:       release.Notes,
+		Prerelease: release.Prerelease,
+	}
+
+	var response githubReleaseResponse
+	if _, err = authorizedRequest(host, "POST", "/repos/"+repo+"/releases", request, &response); err != nil {
+		if len(response.Errors) > 0 {
+			err = fmt.Errorf("%s (%s)", err.Error(), response.Errors[0].Message)
+		}
+
+		return
+	}
+
+	return response.HTMLURL, nil
+}
+
+// createGitLabRelease creates a gitlab release. Gitlab has no pre-release flag, so pre-releases are marked in the name
+func (file *FileWrapper) createGitLabRelease(host string, release Release) (releaseURL string, err error) {
+	// Gitlab projects may be nested in subgroups
+	base, _ := SplitMajorPath(file.GetGoURL())
+	project := strings.TrimPrefix(base, host+"/")
+
+	if release.Prerelease {
+		release.Name += " (pre-release)"
+	}
+
+	request := &gitlabReleaseRequest{
+		TagName:     release.Tag,
+		Name:        release.Name,
+		Description: release.Notes,
+	}
+
+	var response gitlabReleaseResponse
+	if _, err = authorizedRequest(host, "POST", "/projects/"+url.PathEscape(project)+"/releases", request, &response); err != nil {
+		if response.Message != nil {
+			err = fmt.Errorf("%s (%v)", err.Error(), response.Message)
+		}
+
+		return
+	}
+
+	return response.Links.Self, nil
+}
@@ -0,0 +1,59 @@
+package com
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCreateRelease(t *testing.T) {
+	api := testAPI(t, "github.com", map[string]fakeResponse{
+		"POST /repos/org/repo/releases": {Status: 201, Body: githubReleaseResponse{HTMLURL: "https://github.com/org/repo/releases/v1.2.0-rc.1"}},
+	})
+	file := &FileWrapper{goURL: "github.com/org/repo/v2"}
+
+	releaseURL, err := file.CreateRelease(Release{Tag: "v1.2.0-rc.1", Notes: "- fix: nil check", Prerelease: true})
+	if err != nil || releaseURL != "https://github.com/org/repo/releases/v1.2.0-rc.1" {
+		t.Fatalf("CreateRelease() = %s, %v", releaseURL, err)
+	}
+
+	body, _ := api.request("POST /repos/org/repo/releases")
+
+	var request githubReleaseRequest
+	json.Unmarshal([]byte(body), &request)
+	if request != (githubReleaseRequest{TagName: "v1.2.0-rc.1", Name: "v1.2.0-rc.1", Body: "- fix: nil check", Prerelease: true}) {
+		t.Errorf("release request = %+v", request)
+	}
+}
+
+func TestCreateReleaseError(t *testing.T) {
+	testAPI(t, "github.com", map[string]fakeResponse{
+		"POST /repos/org/repo/releases": {Status: 422, Body: githubReleaseResponse{Errors: []PRResponseError{{Message: "already_exists"}}}},
+	})
+	file := &FileWrapper{goURL: "github.com/org/repo"}
+
+	if _, err := file.CreateRelease(Release{Tag: "v1.2.0"}); err == nil || !strings.Contains(err.Error(), "already_exists") {
+		t.Errorf("CreateRelease() = %v, want the api error message", err)
+	}
+}
+
+func TestCreateGitLabRelease(t *testing.T) {
+	api := testAPI(t, "gitlab.com", map[string]fakeResponse{
+		"POST /projects/group%2Fsub%2Frepo/releases": {Status: 201, Body: map[string]interface{}{"_links": map[string]string{"self": "https://gitlab.com/group/sub/repo/-/releases/v2.1.0-rc.1"}}},
+	})
+	file := &FileWrapper{goURL: "gitlab.com/group/sub/repo/v2"}
+
+	releaseURL, err := file.CreateRelease(Release{Tag: "v2.1.0-rc.1", Name: "Next", Notes: "Notes", Prerelease: true})
+	if err != nil || releaseURL != "https://gitlab.com/group/sub/repo/-/releases/v2.1.0-rc.1" {
+		t.Fatalf("CreateRelease() = %s, %v", releaseURL, err)
+	}
+
+	body, _ := api.request("POST /projects/group%2Fsub%2Frepo/releases")
+
+	// GitLab has no pre-release flag
+	var request gitlabReleaseRequest
+	json.Unmarshal([]byte(body), &request)
+	if request != (gitlabReleaseRequest{TagName: "v2.1.0-rc.1", Name: "Next (pre-release)", Description: "Notes"}) {
+		t.Errorf("release request = %+v", request)
+	}
+}
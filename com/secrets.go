@@ -0,0 +1,380 @@
+package com
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// Secret targets
+const (
+	// SecretRepo targets repository secrets
+	SecretRepo = "repo"
+	// SecretOrg targets organization secrets
+	SecretOrg = "org"
+	// SecretEnv targets deployment environment secrets
+	SecretEnv = "env"
+)
+
+// SecretScope represents where a secret is stored
+type SecretScope struct {
+	// Target is repo (default), org or env
+	Target string
+	// Environment name for env secrets
+	Environment string
+	// RepositoryIDs are granted access to org secrets, keeping repositories already selected. Visible to all repositories if empty
+	RepositoryIDs []int64
+}
+
+type publicKeyResponse struct {
+	Key   string `json:"key"`
+	KeyID string `json:"key_id"`
+}
+
+type secretRequest struct {
+	Encrypted string `json:"encrypted_value"`
+	KeyID     string `json:"key_id"`
+
+	Visibility            string  `json:"visibility,omitempty"`
+	SelectedRepositoryIDs []int64 `json:"selected_repository_ids,omitempty"`
+}
+
+type secretsResponse struct {
+	TotalCount int      `json:"total_count"`
+	Secrets    []Secret `json:"secrets"`
+}
+
+type repositoryResponse struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+	Private  bool   `json:"private"` // Also set for internal repositories
+}
+
+type secretRepositoriesResponse struct {
+	TotalCount   int                  `json:"total_count"`
+	Repositories []repositoryResponse `json:"repositories"`
+}
+
+// Secret represents an actions secret. Values are never returned by the api
+type Secret struct {
+	Name       string    `json:"name"`
+	Visibility string    `json:"visibility,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Org returns the git host and owner of the repository
+func (file *FileWrapper) Org() (host, org string) {
+	host, repo := file.repoComponents()
+	org = strings.Split(repo, "/")[0]
+	return
+}
+
+// RepoID returns the api id of the repository
+func (file *FileWrapper) RepoID() (id int64, err error) {
+	repository, err := file.repository()
+	return repository.ID, err
+}
+
+// repository returns the api details of the repository
+func (file *FileWrapper) repository() (response repositoryResponse, err error) {
+	host, repo, err := file.forgeRepo(FeatureSecrets)
+	if err != nil {
+		return
+	}
+
+	_, err = authorizedRequest(host, "GET", "/repos/"+repo, nil, &response)
+	return
+}
+
+// secretsResource returns the actions secrets resource for the provided scope
+func (file *FileWrapper) secretsResource(scope SecretScope) (host, resource string, err error) {
+	host, repo, err := file.forgeRepo(FeatureSecrets)
+	if err != nil {
+		return
+	}
+
+	switch scope.Target {
+	case SecretOrg:
+		_, org := file.Org()
+		resource = "/orgs/" + org + "/actions/secrets"
+	case SecretEnv:
+		if len(scope.Environment) == 0 {
+			err = fmt.Errorf("no environment provided for environment secret")
+			return
+		}
+
+		var id int64
+		if id, err = file.RepoID(); err != nil {
+			return
+		}
+
+		resource = "/repositories/" + strconv.FormatInt(id, 10) + "/environments/" + url.PathEscape(scope.Environment) + "/secrets"
+	case SecretRepo, "":
+		resource = "/repos/" + repo + "/actions/secrets"
+	default:
+		err = fmt.Errorf("unknown secret target %s", scope.Target)
+	}
+
+	return
+}
+
+// publicKey returns the key id and base64 public key used to encrypt secrets for a secrets resource
+func publicKey(host, resource string) (id, key string, err error) {
+	var response publicKeyResponse
+	if _, err = authorizedRequest(host, "GET", resource+"/public-key", nil, &response); err != nil {
+		return
+	}
+
+	if len(response.Key) == 0 {
+		err = fmt.Errorf("no public key returned")
+		return
+	}
+
+	return response.KeyID, response.Key, nil
+}
+
+// GetPublicKey returns the key id and base64 public key used to encrypt secrets for the repository
+func (file *FileWrapper) GetPublicKey() (id, key string, err error) {
+	host, resource, err := file.secretsResource(SecretScope{})
+	if err != nil {
+		return
+	}
+
+	return publicKey(host, resource)
+}
+
+// Encrypt seals a secret for the provided base64 public key using a libsodium sealed box, and returns the base64 encrypted value
+func Encrypt(secret, key string) (encrypted string, err error) {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decoded) != 32 {
+		err = fmt.Errorf("invalid public key")
+		return
+	}
+
+	var publicKey [32]byte
+	copy(publicKey[:], decoded)
+
+	sealed, err := box.SealAnonymous(nil, []byte(secret), &publicKey, rand.Reader)
+	if err != nil {
+		return
+	}
+
+	encrypted = base64.StdEncoding.EncodeToString(sealed)
+	return
+}
+
+// AddSecret will set a secret for the repository
+func (file *FileWrapper) AddSecret(name, secret string) (err error) {
+	return file.AddSecretTo(SecretScope{}, name, secret)
+}
+
+// AddSecretTo encrypts and sets a secret in the provided scope
+func (file *FileWrapper) AddSecretTo(scope SecretScope, name, secret string) (err error) {
+	host, resource, err := file.secretsResource(scope)
+	if err != nil {
+		return
+	}
+
+	id, key, err := publicKey(host, resource)
+	if err != nil {
+		return fmt.Errorf("Unable to get public key: %v", err)
+	}
+
+	encrypted, err := Encrypt(secret, key)
+	if err != nil {
+		return fmt.Errorf("Unable to encrypt secret: %v", err)
+	}
+
+	request := &secretRequest{Encrypted: encrypted, KeyID: id}
+	if scope.Target == SecretOrg {
+		if err = orgSecretVisibility(host, resource, name, scope.RepositoryIDs, request); err != nil {
+			return fmt.Errorf("Unable to read secret visibility: %v", err)
+		}
+	}
+
+	file.Output("Setting " + scope.label() + " secret " + name + "...")
+	if _, err = authorizedRequest(host, "PUT", resource+"/"+name, request, nil); err != nil {
+		return
+	}
+
+	file.Output("Successfully set " + scope.label() + " secret!")
+	return
+}
+
+// orgSecretVisibility sets the visibility of an org secret request. Existing secrets keep their visibility,
+// and selected repositories are merged so other repositories keep access
+func orgSecretVisibility(host, resource, name string, repositoryIDs []int64, request *secretRequest) (err error) {
+	if len(repositoryIDs) == 0 {
+		request.Visibility = "all"
+		return
+	}
+
+	var existing Secret
+	status, err := authorizedRequest(host, "GET", resource+"/"+name, nil, &existing)
+	switch {
+	case status == 404:
+		// New secret, only visible to the provided repositories
+		request.Visibility = "selected"
+		request.SelectedRepositoryIDs = repositoryIDs
+		return nil
+	case err != nil:
+		return
+	case existing.Visibility != "selected":
+		// Already visible to all (or all private) repositories
+		request.Visibility = existing.Visibility
+		return
+	}
+
+	repositories, err := orgSecretRepositories(host, resource, name)
+	if err != nil {
+		return
+	}
+
+	request.Visibility = "selected"
+	selected := make(map[int64]bool)
+	for _, repository := range repositories {
+		selected[repository.ID] = true
+		request.SelectedRepositoryIDs = append(request.SelectedRepositoryIDs, repository.ID)
+	}
+
+	for _, id := range repositoryIDs {
+		if !selected[id] {
+			selected[id] = true
+			request.SelectedRepositoryIDs = append(request.SelectedRepositoryIDs, id)
+		}
+	}
+
+	return
+}
+
+// orgSecretRepositories returns every repository selected for an org secret
+func orgSecretRepositories(host, resource, name string) (repositories []repositoryResponse, err error) {
+	for page := 1; ; page++ {
+		var response secretRepositoriesResponse
+		if _, err = authorizedRequest(host, "GET", pageResource(resource+"/"+name+"/repositories", page), nil, &response); err != nil {
+			return
+		}
+
+		repositories = append(repositories, response.Repositories...)
+		if len(response.Repositories) < perPage || len(repositories) >= response.TotalCount {
+			return
+		}
+	}
+}
+
+// ListSecrets returns the secrets set for the repository
+func (file *FileWrapper) ListSecrets() (secrets []Secret, err error) {
+	return file.ListSecretsIn(SecretScope{})
+}
+
+// ListSecretsIn returns the secrets set in the provided scope
+func (file *FileWrapper) ListSecretsIn(scope SecretScope) (secrets []Secret, err error) {
+	host, resource, err := file.secretsResource(scope)
+	if err != nil {
+		return
+	}
+
+	for page := 1; ; page++ {
+		var response secretsResponse
+		if _, err = authorizedRequest(host, "GET", pageResource(resource, page), nil, &response); err != nil {
+			return
+		}
+
+		secrets = append(secrets, response.Secrets...)
+		if len(response.Secrets) < perPage || len(secrets) >= response.TotalCount {
+			return
+		}
+	}
+}
+
+// DeleteSecret removes a secret from the repository
+func (file *FileWrapper) DeleteSecret(name string) (err error) {
+	return file.DeleteSecretFrom(SecretScope{}, name)
+}
+
+// DeleteSecretFrom removes a secret from the provided scope
+func (file *FileWrapper) DeleteSecretFrom(scope SecretScope, name string) (err error) {
+	host, resource, err := file.secretsResource(scope)
+	if err != nil {
+		return
+	}
+
+	file.Output("Deleting " + scope.label() + " secret " + name + "...")
+	if _, err = authorizedRequest(host, "DELETE", resource+"/"+name, nil, nil); err != nil {
+		return
+	}
+
+	file.Output("Successfully deleted " + scope.label() + " secret!")
+	return
+}
+
+// HasSecret returns true if the named secret is available to the repository from the provided scope
+func (file *FileWrapper) HasSecret(scope SecretScope, name string) (found bool, err error) {
+	secrets, err := file.ListSecretsIn(scope)
+	if err != nil {
+		return
+	}
+
+	for _, secret := range secrets {
+		if secret.Name != name {
+			continue
+		}
+
+		switch {
+		case scope.Target != SecretOrg:
+			// Repo and env secrets always apply
+			return true, nil
+		case secret.Visibility == "selected":
+			return file.orgSecretSelects(name)
+		case secret.Visibility == "private":
+			// Only private and internal repositories can use the secret
+			repository, err := file.repository()
+			return repository.Private, err
+		default:
+			return true, nil
+		}
+	}
+
+	return
+}
+
+// orgSecretSelects returns true if the repository is selected for an org secret
+func (file *FileWrapper) orgSecretSelects(name string) (selected bool, err error) {
+	host, resource, err := file.secretsResource(SecretScope{Target: SecretOrg})
+	if err != nil {
+		return
+	}
+
+	repositories, err := orgSecretRepositories(host, resource, name)
+	if err != nil {
+		return
+	}
+
+	_, repo := file.repoComponents()
+	for _, repository := range repositories {
+		if strings.EqualFold(repository.FullName, repo) {
+			return true, nil
+		}
+	}
+
+	return
+}
+
+// label returns a readable name for the scope
+func (scope SecretScope) label() string {
+	switch scope.Target {
+	case SecretOrg:
+		return "organization"
+	case SecretEnv:
+		return scope.Environment + " environment"
+	default:
+		return "repository"
+	}
+}
@@ -0,0 +1,194 @@
+package com
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// testRepositories returns count repositories with ids from first
+func testRepositories(first int64, count int) (repositories []repositoryResponse) {
+	for id := first; id < first+int64(count); id++ {
+		repositories = append(repositories, repositoryResponse{ID: id})
+	}
+
+	return
+}
+
+func TestEncrypt(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := Encrypt("s3cret", base64.StdEncoding.EncodeToString(publicKey[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sealed boxes can only be opened with the repo's private key
+	sealed, _ := base64.StdEncoding.DecodeString(encrypted)
+	if secret, ok := box.OpenAnonymous(nil, sealed, publicKey, privateKey); !ok || string(secret) != "s3cret" {
+		t.Errorf("Encrypt() = %q, %v, want s3cret sealed for the public key", secret, ok)
+	}
+}
+
+func TestEncryptInvalidKey(t *testing.T) {
+	for _, key := range []string{"", "not base64", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := Encrypt("s3cret", key); err == nil {
+			t.Errorf("Encrypt() with key %q = nil, want error", key)
+		}
+	}
+}
+
+func TestOrgSecretVisibility(t *testing.T) {
+	resource := "/orgs/org/actions/secrets"
+	selected := testRepositories(1, 100)
+
+	var selectedIDs []int64
+	for id := int64(1); id <= 101; id++ {
+		selectedIDs = append(selectedIDs, id)
+	}
+
+	tests := []struct {
+		name           string
+		repositoryIDs  []int64
+		routes         map[string]fakeResponse
+		wantVisibility string
+		wantIDs        []int64
+	}{
+		{"visible to all", nil, nil, "all", nil},
+		{"new secret", []int64{7, 8}, map[string]fakeResponse{
+			"GET " + resource + "/TOKEN": {Status: 404, Body: map[string]string{"message": "Not Found"}},
+		}, "selected", []int64{7, 8}},
+		{"keeps all", []int64{7}, map[string]fakeResponse{
+			"GET " + resource + "/TOKEN": {Body: Secret{Name: "TOKEN", Visibility: "all"}},
+		}, "all", nil},
+		{"keeps private", []int64{7}, map[string]fakeResponse{
+			"GET " + resource + "/TOKEN": {Body: Secret{Name: "TOKEN", Visibility: "private"}},
+		}, "private", nil},
+		{"merges selected repositories across pages", []int64{50, 102}, map[string]fakeResponse{
+			"GET " + resource + "/TOKEN":                                  {Body: Secret{Name: "TOKEN", Visibility: "selected"}},
+			"GET " + resource + "/TOKEN/repositories?per_page=100&page=1": {Body: secretRepositoriesResponse{TotalCount: 101, Repositories: selected}},
+			"GET " + resource + "/TOKEN/repositories?per_page=100&page=2": {Body: secretRepositoriesResponse{TotalCount: 101, Repositories: testRepositories(101, 1)}},
+		}, "selected", append(selectedIDs, 102)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testAPI(t, "github.com", test.routes)
+
+			var request secretRequest
+			if err := orgSecretVisibility("github.com", resource, "TOKEN", test.repositoryIDs, &request); err != nil {
+				t.Fatal(err)
+			}
+
+			if request.Visibility != test.wantVisibility || !reflect.DeepEqual(request.SelectedRepositoryIDs, test.wantIDs) {
+				t.Errorf("orgSecretVisibility() = %s %v, want %s %v", request.Visibility, request.SelectedRepositoryIDs, test.wantVisibility, test.wantIDs)
+			}
+		})
+	}
+}
+
+func TestAddOrgSecret(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := testAPI(t, "github.com", map[string]fakeResponse{
+		"GET /orgs/org/actions/secrets/public-key":                             {Body: publicKeyResponse{KeyID: "key-1", Key: base64.StdEncoding.EncodeToString(publicKey[:])}},
+		"GET /orgs/org/actions/secrets/TOKEN":                                  {Body: Secret{Name: "TOKEN", Visibility: "selected"}},
+		"GET /orgs/org/actions/secrets/TOKEN/repositories?per_page=100&page=1": {Body: secretRepositoriesResponse{TotalCount: 1, Repositories: testRepositories(3, 1)}},
+		"PUT /orgs/org/actions/secrets/TOKEN":                                  {Status: 204},
+	})
+	file := &FileWrapper{goURL: "github.com/org/repo"}
+
+	if err = file.AddSecretTo(SecretScope{Target: SecretOrg, RepositoryIDs: []int64{9}}, "TOKEN", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := api.request("PUT /orgs/org/actions/secrets/TOKEN")
+
+	var request secretRequest
+	if err = json.Unmarshal([]byte(body), &request); err != nil {
+		t.Fatal(err)
+	}
+
+	if request.KeyID != "key-1" || request.Visibility != "selected" || !reflect.DeepEqual(request.SelectedRepositoryIDs, []int64{3, 9}) {
+		t.Errorf("secret request = %+v, want key-1 selected for [3 9]", request)
+	}
+
+	sealed, _ := base64.StdEncoding.DecodeString(request.Encrypted)
+	if secret, ok := box.OpenAnonymous(nil, sealed, publicKey, privateKey); !ok || string(secret) != "s3cret" {
+		t.Errorf("encrypted value = %q, %v, want s3cret sealed for the public key", secret, ok)
+	}
+}
+
+func TestHasSecret(t *testing.T) {
+	secrets := secretsResponse{TotalCount: 4, Secrets: []Secret{
+		{Name: "ALL", Visibility: "all"},
+		{Name: "PRIVATE", Visibility: "private"},
+		{Name: "SELECTED", Visibility: "selected"},
+		{Name: "OTHER", Visibility: "selected"},
+	}}
+
+	tests := []struct {
+		name    string
+		scope   SecretScope
+		secret  string
+		private bool
+		want    bool
+	}{
+		{"repo secret", SecretScope{}, "DEPLOY", false, true},
+		{"missing repo secret", SecretScope{}, "MISSING", false, false},
+		{"org secret visible to all", SecretScope{Target: SecretOrg}, "ALL", false, true},
+		{"private org secret in a private repo", SecretScope{Target: SecretOrg}, "PRIVATE", true, true},
+		{"private org secret in a public repo", SecretScope{Target: SecretOrg}, "PRIVATE", false, false},
+		{"selected org secret", SecretScope{Target: SecretOrg}, "SELECTED", false, true},
+		{"org secret selecting other repos", SecretScope{Target: SecretOrg}, "OTHER", false, false},
+		{"missing org secret", SecretScope{Target: SecretOrg}, "MISSING", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testAPI(t, "github.com", map[string]fakeResponse{
+				"GET /repos/org/repo/actions/secrets?per_page=100&page=1": {Body: secretsResponse{TotalCount: 1, Secrets: []Secret{{Name: "DEPLOY"}}}},
+				"GET /orgs/org/actions/secrets?per_page=100&page=1":       {Body: secrets},
+				"GET /repos/org/repo": {Body: repositoryResponse{ID: 3, FullName: "org/repo", Private: test.private}},
+				"GET /orgs/org/actions/secrets/SELECTED/repositories?per_page=100&page=1": {Body: secretRepositoriesResponse{TotalCount: 2, Repositories: []repositoryResponse{
+					{ID: 2, FullName: "org/other"},
+					{ID: 3, FullName: "Org/Repo"},
+				}}},
+				"GET /orgs/org/actions/secrets/OTHER/repositories?per_page=100&page=1": {Body: secretRepositoriesResponse{TotalCount: 1, Repositories: []repositoryResponse{
+					{ID: 2, FullName: "org/other"},
+				}}},
+			})
+			file := &FileWrapper{goURL: "github.com/org/repo"}
+
+			if found, err := file.HasSecret(test.scope, test.secret); err != nil || found != test.want {
+				t.Errorf("HasSecret() = %v, %v, want %v", found, err, test.want)
+			}
+		})
+	}
+}
+
+func TestEnvironmentSecretsResource(t *testing.T) {
+	testAPI(t, "github.com", map[string]fakeResponse{
+		"GET /repos/org/repo": {Body: repositoryResponse{ID: 42, FullName: "org/repo"}},
+	})
+	file := &FileWrapper{goURL: "github.com/org/repo"}
+
+	_, resource, err := file.secretsResource(SecretScope{Target: SecretEnv, Environment: "prod eu"})
+	if err != nil || resource != "/repositories/42/environments/prod%20eu/secrets" {
+		t.Errorf("secretsResource() = %s, %v", resource, err)
+	}
+
+	if _, _, err = file.secretsResource(SecretScope{Target: SecretEnv}); err == nil {
+		t.Errorf("secretsResource() without an environment = nil, want error")
+	}
+}
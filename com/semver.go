@@ -0,0 +1,327 @@
+package com
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version represents a semantic version tag (vMAJOR.MINOR.PATCH[-PRERELEASE][+BUILD])
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+
+	Prerelease string
+
+	// Build metadata of the tag, ignored for precedence
+	Build string
+}
+
+// ParseVersion parses a semantic version tag. Returns false if tag is not a valid vX.Y.Z version
+func ParseVersion(tag string) (version Version, ok bool) {
+	if !strings.HasPrefix(tag, "v") {
+		return
+	}
+
+	core := tag[1:]
+
+	// Build metadata is ignored for precedence
+	if index := strings.Index(core, "+"); index >= 0 {
+		version.Build = core[index+1:]
+		core = core[:index]
+
+		if len(version.Build) == 0 {
+			return
+		}
+	}
+
+	if index := strings.Index(core, "-"); index >= 0 {
+		version.Prerelease = core[index+1:]
+		core = core[:index]
+
+		if len(version.Prerelease) == 0 {
+			return
+		}
+	}
+
+	comps := strings.Split(core, ".")
+	if len(comps) != 3 {
+		return
+	}
+
+	numbers := make([]int, 3)
+	for i, comp := range comps {
+		if len(comp) == 0 || (len(comp) > 1 && comp[0] == '0') {
+			// Leading zeros are invalid
+			return
+		}
+
+		number, err := strconv.Atoi(comp)
+		if err != nil || number < 0 {
+			return
+		}
+
+		numbers[i] = number
+	}
+
+	version.Major, version.Minor, version.Patch = numbers[0], numbers[1], numbers[2]
+	ok = true
+	return
+}
+
+// String returns the version as a tag, without build metadata
+func (version Version) String() string {
+	tag := "v" + strconv.Itoa(version.Major) + "." + strconv.Itoa(version.Minor) + "." + strconv.Itoa(version.Patch)
+	if len(version.Prerelease) > 0 {
+		tag += "-" + version.Prerelease
+	}
+
+	return tag
+}
+
+// Tag returns the original tag of a parsed version, including build metadata
+func (version Version) Tag() string {
+	if len(version.Build) > 0 {
+		return version.String() + "+" + version.Build
+	}
+
+	return version.String()
+}
+
+// IsPrerelease returns true if the version has a pre-release suffix
+func (version Version) IsPrerelease() bool {
+	return len(version.Prerelease) > 0
+}
+
+// Compare returns -1, 0 or 1 if version has lower, equal or higher precedence than other
+func (version Version) Compare(other Version) int {
+	if result := compareInt(version.Major, other.Major); result != 0 {
+		return result
+	}
+	if result := compareInt(version.Minor, other.Minor); result != 0 {
+		return result
+	}
+	if result := compareInt(version.Patch, other.Patch); result != 0 {
+		return result
+	}
+
+	return comparePrerelease(version.Prerelease, other.Prerelease)
+}
+
+// Distance returns the most significant component that differs from other: major, minor, patch, prerelease, or empty if equal
+func (version Version) Distance(other Version) string {
+	switch {
+	case version.Major != other.Major:
+		return "major"
+	case version.Minor != other.Minor:
+		return "minor"
+	case version.Patch != other.Patch:
+		return "patch"
+	case version.Prerelease != other.Prerelease:
+		return "prerelease"
+	default:
+		return ""
+	}
+}
+
+// Increment returns the next version for the provided level: major, minor or patch
+func (version Version) Increment(level string) (next Version) {
+	next = version
+	next.Prerelease = ""
+	next.Build = ""
+
+	switch level {
+	case "major":
+		if version.IsPrerelease() && version.Minor == 0 && version.Patch == 0 {
+			// v2.0.0-rc.1 -> v2.0.0
+			return
+		}
+
+		next.Major++
+		next.Minor = 0
+		next.Patch = 0
+	case "minor":
+		if version.IsPrerelease() && version.Patch == 0 {
+			// v1.2.0-rc.1 -> v1.2.0
+			return
+		}
+
+		next.Minor++
+		next.Patch = 0
+	default:
+		if version.IsPrerelease() {
+			// v1.2.3-rc.1 -> v1.2.3
+			return
+		}
+
+		next.Patch++
+	}
+
+	return
+}
+
+// Bump returns the next version for the provided level: major, minor, patch or pre.
+// Providing a pre-release identifier (rc, beta, etc) creates or continues a pre-release series
+func (version Version) Bump(level, identifier string) (next Version) {
+	if level == "pre" {
+		if len(identifier) == 0 {
+			if identifier = prereleaseIdentifier(version.Prerelease); len(identifier) == 0 {
+				identifier = "rc"
+			}
+		}
+
+		if !version.IsPrerelease() {
+			// Start a pre-release series for the next patch
+			next = version.Increment("patch")
+			next.Prerelease = identifier + ".1"
+			return
+		}
+
+		next = version
+		next.Prerelease = nextPrerelease(version.Prerelease, identifier)
+		next.Build = ""
+		return
+	}
+
+	next = version.Increment(level)
+	if len(identifier) == 0 {
+		return
+	}
+
+	if version.IsPrerelease() && next.Major == version.Major && next.Minor == version.Minor && next.Patch == version.Patch {
+		// Continue the current pre-release series
+		next.Prerelease = nextPrerelease(version.Prerelease, identifier)
+	} else {
+		next.Prerelease = identifier + ".1"
+	}
+
+	return
+}
+
+// NextVersion returns latest bumped by level (patch when empty). Returns an error if the result can't be tagged
+// for the module path: versions must increase, and majors above v1 require a /vN module path
+func NextVersion(latest Version, modulePath, level, identifier string) (next Version, err error) {
+	if len(level) == 0 {
+		level = "patch"
+	}
+
+	next = latest.Bump(level, identifier)
+	if next.Compare(latest) <= 0 {
+		err = fmt.Errorf("%s does not follow %s", next, latest.Tag())
+		return
+	}
+
+	if !MatchesMajor(modulePath, next.Major) {
+		err = fmt.Errorf("%s requires module path %s. Run the major action to migrate", next, MajorPath(modulePath, next.Major))
+	}
+
+	return
+}
+
+// prereleaseIdentifier returns the identifier of a pre-release (rc.2 -> rc)
+func prereleaseIdentifier(prerelease string) string {
+	if index := strings.LastIndex(prerelease, "."); index >= 0 {
+		if _, err := strconv.Atoi(prerelease[index+1:]); err == nil {
+			return prerelease[:index]
+		}
+	}
+
+	return prerelease
+}
+
+// nextPrerelease increments the counter of a pre-release if identifiers match (rc.2 -> rc.3), or starts a new counter
+func nextPrerelease(prerelease, identifier string) string {
+	if index := strings.LastIndex(prerelease, "."); index >= 0 && prerelease[:index] == identifier {
+		if counter, err := strconv.Atoi(prerelease[index+1:]); err == nil {
+			return identifier + "." + strconv.Itoa(counter+1)
+		}
+	}
+
+	return identifier + ".1"
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePrerelease compares pre-release identifiers. Releases have higher precedence than pre-releases
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+
+		var result int
+		switch {
+		case aErr == nil && bErr == nil:
+			result = compareInt(aNum, bNum)
+		case aErr == nil:
+			// Numeric identifiers have lower precedence
+			result = -1
+		case bErr == nil:
+			result = 1
+		default:
+			result = strings.Compare(aIDs[i], bIDs[i])
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+
+	return compareInt(len(aIDs), len(bIDs))
+}
+
+// AtMost returns true if version is at or below max. Partial max versions (v1, v1.4) include all later components (v1.4 allows v1.4.9).
+// Returns false for valid if max can't be parsed
+func (version Version) AtMost(max string) (ok, valid bool) {
+	if !strings.HasPrefix(max, "v") {
+		return
+	}
+
+	comps := strings.Split(max[1:], ".")
+	if len(comps) == 3 {
+		var maxVersion Version
+		if maxVersion, valid = ParseVersion(max); valid {
+			ok = version.Compare(maxVersion) <= 0
+		}
+
+		return
+	}
+
+	if len(comps) > 2 {
+		return
+	}
+
+	current := []int{version.Major, version.Minor}
+	for i, comp := range comps {
+		number, err := strconv.Atoi(comp)
+		if err != nil || number < 0 {
+			return
+		}
+
+		if result := compareInt(current[i], number); result != 0 {
+			return result < 0, true
+		}
+	}
+
+	return true, true
+}
@@ -0,0 +1,214 @@
+package com
+
+import "testing"
+
+func mustParse(t *testing.T, tag string) Version {
+	t.Helper()
+
+	version, ok := ParseVersion(tag)
+	if !ok {
+		t.Fatalf("invalid version %s", tag)
+	}
+
+	return version
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Version
+		ok   bool
+	}{
+		{"v1.2.3", Version{Major: 1, Minor: 2, Patch: 3}, true},
+		{"v0.0.0", Version{}, true},
+		{"v1.2.3-rc.1", Version{Major: 1, Minor: 2, Patch: 3, Prerelease: "rc.1"}, true},
+		{"v1.2.3+build.5", Version{Major: 1, Minor: 2, Patch: 3, Build: "build.5"}, true},
+		{"v1.2.3-beta+exp", Version{Major: 1, Minor: 2, Patch: 3, Prerelease: "beta", Build: "exp"}, true},
+		{"1.2.3", Version{}, false},
+		{"v1.2", Version{}, false},
+		{"v1.2.3.4", Version{}, false},
+		{"v01.2.3", Version{}, false},
+		{"v1.2.3-", Version{}, false},
+		{"v1.2.3+", Version{}, false},
+		{"v1.x.3", Version{}, false},
+		{"v-1.2.3", Version{}, false},
+		{"", Version{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			got, ok := ParseVersion(test.tag)
+			if ok != test.ok {
+				t.Fatalf("ParseVersion() ok = %v, want %v", ok, test.ok)
+			}
+
+			if ok && got != test.want {
+				t.Errorf("ParseVersion() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestVersionTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		string string
+	}{
+		{"v1.2.3", "v1.2.3"},
+		{"v1.2.3-rc.1", "v1.2.3-rc.1"},
+		{"v1.2.3+build.5", "v1.2.3"},
+		{"v1.2.3-rc.1+build.5", "v1.2.3-rc.1"},
+	}
+
+	for _, test := range tests {
+		version := mustParse(t, test.tag)
+		if got := version.Tag(); got != test.tag {
+			t.Errorf("%s Tag() = %s", test.tag, got)
+		}
+
+		if got := version.String(); got != test.string {
+			t.Errorf("%s String() = %s, want %s", test.tag, got, test.string)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "v1.2.4", -1},
+		{"v1.3.0", "v1.2.9", 1},
+		{"v2.0.0", "v1.9.9", 1},
+		{"v1.2.3-rc.1", "v1.2.3", -1},
+		{"v1.2.3-alpha", "v1.2.3-beta", -1},
+		{"v1.2.3-rc.2", "v1.2.3-rc.10", -1},
+		{"v1.2.3-rc.1", "v1.2.3-rc", 1},
+		{"v1.2.3-1", "v1.2.3-alpha", -1},
+		{"v1.2.3+a", "v1.2.3+b", 0},
+	}
+
+	for _, test := range tests {
+		if got := mustParse(t, test.a).Compare(mustParse(t, test.b)); got != test.want {
+			t.Errorf("%s Compare(%s) = %d, want %d", test.a, test.b, got, test.want)
+		}
+
+		if got := mustParse(t, test.b).Compare(mustParse(t, test.a)); got != -test.want {
+			t.Errorf("%s Compare(%s) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	tests := []struct {
+		version    string
+		level      string
+		identifier string
+		want       string
+	}{
+		{"v1.2.3", "patch", "", "v1.2.4"},
+		{"v1.2.3", "minor", "", "v1.3.0"},
+		{"v1.2.3", "major", "", "v2.0.0"},
+		{"v1.2.3+build", "patch", "", "v1.2.4"},
+		{"v1.2.3-rc.1", "patch", "", "v1.2.3"},
+		{"v1.2.0-rc.1", "minor", "", "v1.2.0"},
+		{"v2.0.0-rc.1", "major", "", "v2.0.0"},
+		{"v1.2.3-rc.1", "minor", "", "v1.3.0"},
+		{"v1.2.3", "pre", "", "v1.2.4-rc.1"},
+		{"v1.2.3", "pre", "beta", "v1.2.4-beta.1"},
+		{"v1.2.4-rc.1", "pre", "", "v1.2.4-rc.2"},
+		{"v1.2.4-beta.3", "pre", "rc", "v1.2.4-rc.1"},
+		{"v1.2.3", "minor", "rc", "v1.3.0-rc.1"},
+		{"v1.3.0-rc.1", "minor", "rc", "v1.3.0-rc.2"},
+		{"v1.3.0-rc.1", "major", "rc", "v2.0.0-rc.1"},
+	}
+
+	for _, test := range tests {
+		if got := mustParse(t, test.version).Bump(test.level, test.identifier); got.String() != test.want {
+			t.Errorf("%s Bump(%q, %q) = %s, want %s", test.version, test.level, test.identifier, got, test.want)
+		}
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		latest     string
+		modulePath string
+		level      string
+		identifier string
+		want       string
+		wantErr    bool
+	}{
+		{"v1.2.3", "github.com/x/y", "", "", "v1.2.4", false},
+		{"v1.2.3", "github.com/x/y", "minor", "", "v1.3.0", false},
+		{"v0.4.1", "github.com/x/y", "major", "", "v1.0.0", false},
+		{"v1.2.3", "github.com/x/y", "major", "", "", true},
+		{"v1.2.3", "github.com/x/y/v2", "major", "", "v2.0.0", false},
+		{"v2.1.0", "github.com/x/y/v2", "patch", "", "v2.1.1", false},
+		{"v2.1.0", "github.com/x/y/v2", "major", "", "", true},
+	}
+
+	for _, test := range tests {
+		next, err := NextVersion(mustParse(t, test.latest), test.modulePath, test.level, test.identifier)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("NextVersion(%s, %s, %q) = %s, want error", test.latest, test.modulePath, test.level, next)
+			}
+
+			continue
+		}
+
+		if err != nil || next.String() != test.want {
+			t.Errorf("NextVersion(%s, %s, %q) = %s, %v, want %s", test.latest, test.modulePath, test.level, next, err, test.want)
+		}
+	}
+}
+
+func TestVersionAtMost(t *testing.T) {
+	tests := []struct {
+		version string
+		max     string
+		ok      bool
+		valid   bool
+	}{
+		{"v1.4.9", "v1.4", true, true},
+		{"v1.5.0", "v1.4", false, true},
+		{"v1.3.0", "v1.4", true, true},
+		{"v1.9.9", "v1", true, true},
+		{"v2.0.0", "v1", false, true},
+		{"v1.4.2", "v1.4.2", true, true},
+		{"v1.4.3", "v1.4.2", false, true},
+		{"v1.4.2-rc.1", "v1.4.2", true, true},
+		{"v1.4.2", "1.4", false, false},
+		{"v1.4.2", "v1.x", false, false},
+		{"v1.4.2", "v1.4.2.1", false, false},
+	}
+
+	for _, test := range tests {
+		ok, valid := mustParse(t, test.version).AtMost(test.max)
+		if ok != test.ok || valid != test.valid {
+			t.Errorf("%s AtMost(%s) = %v, %v, want %v, %v", test.version, test.max, ok, valid, test.ok, test.valid)
+		}
+	}
+}
+
+func TestVersionDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"v1.2.3", "v1.2.3", ""},
+		{"v1.2.3", "v1.2.4", "patch"},
+		{"v1.2.3", "v1.3.3", "minor"},
+		{"v1.2.3", "v2.2.3", "major"},
+		{"v1.2.3-rc.1", "v1.2.3", "prerelease"},
+		{"v1.2.3+a", "v1.2.3+b", ""},
+	}
+
+	for _, test := range tests {
+		if got := mustParse(t, test.a).Distance(mustParse(t, test.b)); got != test.want {
+			t.Errorf("%s Distance(%s) = %q, want %q", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package com
+
+import "fmt"
+
+// Signing represents how gomu signs its commits and tags, and the identity it commits as
+type Signing struct {
+	// Sign commits and annotated tags
+	Sign bool
+	// Signing key and format (openpgp, ssh or x509). Default to git config user.signingkey and gpg.format
+	Key    string
+	Format string
+
+	// Author and committer identity. Defaults to git config user.name and user.email
+	Name  string
+	Email string
+}
+
+var signing Signing
+
+// SetSigning sets the signing settings and identity used for all gomu commits and tags
+func SetSigning(settings Signing) {
+	signing = settings
+}
+
+// gitCommand returns a git command with signing and identity config overrides
+func gitCommand(args ...string) []string {
+	command := []string{"git"}
+	if len(signing.Name) > 0 {
+		command = append(command, "-c", "user.name="+signing.Name)
+	}
+	if len(signing.Email) > 0 {
+		command = append(command, "-c", "user.email="+signing.Email)
+	}
+	if signing.Sign && len(signing.Key) > 0 {
+		command = append(command, "-c", "user.signingkey="+signing.Key)
+	}
+	if signing.Sign && len(signing.Format) > 0 {
+		command = append(command, "-c", "gpg.format="+signing.Format)
+	}
+
+	return append(command, args...)
+}
+
+// CheckSigning returns an error if commits can't be signed in the lib's repo
+func (file *FileWrapper) CheckSigning() (err error) {
+	if !signing.Sign || len(signing.Key) > 0 {
+		return
+	}
+
+	if key, _ := file.CmdOutput("git", "config", "--get", "user.signingkey"); len(key) > 0 {
+		return
+	}
+
+	format := signing.Format
+	if len(format) == 0 {
+		format, _ = file.CmdOutput("git", "config", "--get", "gpg.format")
+	}
+
+	if format == "ssh" {
+		// Ssh signing requires a key
+		return fmt.Errorf("no ssh signing key configured (git config user.signingkey)")
+	}
+
+	// Gpg falls back to the default key for the committer email
+	if _, err = file.CmdOutput("gpg", "--list-secret-keys"); err != nil {
+		err = fmt.Errorf("no signing key configured (git config user.signingkey)")
+	}
+
+	return
+}
@@ -0,0 +1,106 @@
+package com
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tags returns all tags reachable from HEAD
+func (file *FileWrapper) Tags() (tags []string, err error) {
+	return file.MergedTags("HEAD")
+}
+
+// MergedTags returns all tags reachable from a revision
+func (file *FileWrapper) MergedTags(rev string) (tags []string, err error) {
+	output, err := file.CmdOutput("git", "tag", "--merged", rev)
+	if err != nil {
+		return
+	}
+
+	for _, tag := range strings.Split(output, "\n") {
+		if tag = strings.TrimSpace(tag); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+
+	return
+}
+
+// Versions returns the semantic version tags reachable from HEAD
+func (file *FileWrapper) Versions() (versions []Version, err error) {
+	tags, err := file.Tags()
+	if err != nil {
+		return
+	}
+
+	for _, tag := range tags {
+		version, ok := ParseVersion(tag)
+		if !ok {
+			// Ignore non-semver tags
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	return
+}
+
+// LatestVersion returns the highest semantic version tag reachable from HEAD
+func (file *FileWrapper) LatestVersion() (latest Version, found bool) {
+	versions, err := file.Versions()
+	if err != nil {
+		return
+	}
+
+	for _, version := range versions {
+		if !found || version.Compare(latest) > 0 {
+			latest = version
+			found = true
+		}
+	}
+
+	return
+}
+
+// TagCommit returns the commit hash for a tag
+func (file *FileWrapper) TagCommit(tag string) (commit string, err error) {
+	return file.CmdOutput("git", "rev-list", "-n", "1", tag)
+}
+
+// HeadCommit returns the commit hash of HEAD
+func (file *FileWrapper) HeadCommit() (commit string, err error) {
+	return file.CmdOutput("git", "rev-parse", "HEAD")
+}
+
+// CreateTag creates an annotated tag at HEAD. Signs the tag if signing is set
+func (file *FileWrapper) CreateTag(tag, message string) (err error) {
+	if len(message) == 0 {
+		message = tag
+	}
+
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	// Verbatim keeps markdown headings in changelog annotations
+	annotate := "-a"
+	if signing.Sign {
+		annotate = "-s"
+	}
+
+	if err = file.RunCmd(gitCommand("tag", annotate, tag, "--cleanup=verbatim", "-m", message)...); err != nil {
+		err = fmt.Errorf("Unable to create tag %s", tag)
+	}
+
+	return
+}
+
+// PushTag pushes a single tag to origin
+func (file *FileWrapper) PushTag(tag string) (err error) {
+	if err = file.RunCmd("git", "push", "origin", "refs/tags/"+tag); err != nil {
+		err = fmt.Errorf("Unable to push tag %s", tag)
+	}
+
+	return
+}
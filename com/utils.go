@@ -16,6 +16,9 @@ func (file *FileWrapper) RunCmd(args ...string) (err error) {
 
 	cmd := exec.Command(name, params...)
 	cmd.Dir = file.Path
+	if name == "go" {
+		cmd.Env = GoEnviron()
+	}
 	if err = cmd.Run(); err != nil {
 		return file.handleError(tag, err)
 	}
@@ -25,6 +28,17 @@ func (file *FileWrapper) RunCmd(args ...string) (err error) {
 
 // CmdOutput returns output of a shell command at the file's path
 func (file *FileWrapper) CmdOutput(args ...string) (output string, err error) {
+	stdout, err := file.CmdBytes(args...)
+	if err != nil {
+		return
+	}
+
+	output = strings.TrimSpace(string(stdout))
+	return
+}
+
+// CmdBytes returns the untrimmed output of a shell command at the file's path
+func (file *FileWrapper) CmdBytes(args ...string) (stdout []byte, err error) {
 	name := args[0]
 	params := args[1:]
 
@@ -33,13 +47,13 @@ func (file *FileWrapper) CmdOutput(args ...string) (output string, err error) {
 
 	cmd := exec.Command(name, params...)
 	cmd.Dir = file.Path
-	stdout, err := cmd.Output()
-	if err != nil {
+	if name == "go" {
+		cmd.Env = GoEnviron()
+	}
+	if stdout, err = cmd.Output(); err != nil {
 		err = file.handleError(tag, err)
-		return
 	}
 
-	output = strings.TrimSpace(string(stdout))
 	return
 }
 
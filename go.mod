@@ -1,8 +1,12 @@
 module github.com/hatchify/mod-utils
 
-go 1.14
+go 1.17
 
 require (
 	github.com/hatchify/closer v0.4.79
 	github.com/remeh/sizedwaitgroup v1.0.0
+	golang.org/x/crypto v0.1.0
+	golang.org/x/mod v0.8.0
 )
+
+require golang.org/x/sys v0.1.0 // indirect
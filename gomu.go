@@ -26,6 +26,18 @@ type MU struct {
 	Errors []error
 
 	closer *closer.Closer
+
+	orgSecretLibs []Library
+
+	targets         map[string]bool
+	majorMigrations []majorMigration
+	upgrades        []moduleUpgrade
+
+	siblingVersions map[string][]com.Version
+	proxyVersions   map[string]string
+
+	// Module path -> version -> libs
+	requirements map[string]map[string][]string
 }
 
 var closed = false
@@ -74,19 +86,17 @@ func (mu *MU) performThenClose() {
 }
 
 func (mu *MU) perform() {
-	if mu.Options.PullRequest {
-		authObject, err := com.LoadAuth()
-		if err != nil || len(authObject.User) == 0 || len(authObject.Token) == 0 {
-			com.Println("")
-			com.Println("gomu :: I needs credentials for Pull Requests...")
-			if authObject.Setup() != nil {
-				com.Println("Error saving :(")
-				err = fmt.Errorf("Unable to parse github username and token")
-				return
-			}
-			err = nil
-			com.Println("Saved Credentials!")
-		}
+	if mu.Options.NonInteractive {
+		// Fail instead of prompting for credentials
+		com.SetInteractive(false)
+	}
+
+	com.SetSigning(mu.Options.Signing())
+
+	if err := mu.Options.Validate(); err != nil {
+		com.Println("\nInvalid options :( " + err.Error())
+		mu.Errors = append(mu.Errors, err)
+		return
 	}
 
 	if len(mu.Options.TargetDirectories) > 0 {
@@ -113,6 +123,11 @@ func (mu *MU) perform() {
 		branch = "\"current\""
 	}
 
+	if mu.Options.Action == "upgrade" && len(mu.Options.FilterDependencies) == 0 {
+		// Find libs requiring the upgraded modules
+		mu.Options.FilterDependencies = mu.upgradePaths()
+	}
+
 	// Sort libs
 	var fileHead *sort.FileNode
 	if mu.Options.DirectImport {
@@ -123,7 +138,61 @@ func (mu *MU) perform() {
 		fileHead, mu.Stats.DepCount = libs.SortedRecursiveDeps(mu.Options.FilterDependencies)
 	}
 
-	if len(mu.Options.FilterDependencies) == 0 || len(mu.Options.FilterDependencies) == 0 {
+	com.SetGoEnv(mu.Options.GoEnv(fileHead))
+
+	if mu.Options.Action == "auth" {
+		// Only verify credentials
+		com.Println("")
+		mu.verifyCredentials(fileHead)
+		return
+	}
+
+	if mu.Options.Action == "serve-proxy" {
+		// Serve until interrupted
+		com.Println("")
+		mu.serveProxy(fileHead)
+		return
+	}
+
+	if len(mu.Options.forgeFeatures()) > 0 && !mu.ensureCredentials(fileHead) {
+		return
+	}
+
+	if mu.Options.Action == "upgrade" && !mu.parseUpgrades(fileHead) {
+		com.Println("\nUpgrade action requires modules to upgrade. Nothing to do :(")
+		return
+	}
+
+	if mu.Options.Action == "go-version" && !com.IsGoVersion(mu.Options.GoVersion) {
+		com.Println("\ngo-version action requires a target go version (1.22, 1.22.3). Nothing to do :(")
+		return
+	}
+
+	switch mu.Options.Action {
+	case "major", "retract", "deprecate":
+		if mu.findTargets(fileHead) == 0 {
+			com.Println("\n" + mu.Options.Action + " action requires libs to update. Nothing to do :(")
+			return
+		}
+
+		// Changes are published (and picked up by dependants) with new tags
+		mu.Options.Tag = true
+		mu.Stats.Options.Tag = true
+	}
+
+	if mu.Options.Cascade && !mu.Options.Pseudo {
+		// Dependants need new tags (or pseudo-versions) to pick up merged changes
+		mu.Options.Tag = true
+		if mu.Stats.Options != nil {
+			mu.Stats.Options.Tag = true
+		}
+	}
+
+	if mu.Options.Sign && !mu.ensureSigning(fileHead) {
+		return
+	}
+
+	if len(mu.Options.FilterDependencies) == 0 {
 		com.Println("\nPerforming", mu.Options.Action, "on "+branch+" branch for", mu.Stats.DepCount, "lib(s)")
 	} else {
 		com.Println("\nPerforming", mu.Options.Action, "on "+branch+" branch for", mu.Stats.DepCount, "lib(s) depending on", mu.Options.FilterDependencies)
@@ -133,7 +202,7 @@ func (mu *MU) perform() {
 	// Eventual "undo" action possibly?
 	// TODO: Move warning checks to client instead of utils lib, handle differently in plugin vs cli. Slack approval like release train?
 	switch mu.Options.Action {
-	case "sync":
+	case "sync", "upgrade":
 		warningLibs := make([]string, mu.Stats.DepCount)
 		count := 0
 		for itr := fileHead; itr != nil; itr = itr.Next {
@@ -144,26 +213,207 @@ func (mu *MU) perform() {
 		com.Println(strings.Join(warningLibs, "\n"))
 
 		warningActions := []string{"Sync action will:"}
+		if mu.Options.Action == "upgrade" {
+			warningActions[0] = "Upgrade action will:"
+		}
 		if mu.Options.Branch != "" {
 			warningActions = append(warningActions, "- checkout (or create) branch "+mu.Options.Branch)
 		}
+		for _, upgrade := range mu.upgrades {
+			warningActions = append(warningActions, "- upgrade "+upgrade.String()+" where required")
+		}
 		warningActions = append(warningActions, "- update mod files")
+		if mu.Options.LocalProxy {
+			warningActions = append(warningActions, "- resolve local libs through a module proxy serving their repos")
+		}
 		if mu.Options.Commit {
 			warningActions = append(warningActions, "- commit local changes (if any)")
 		}
+		warningActions = append(warningActions, mu.Options.signingActions()...)
 		if mu.Options.PullRequest {
-			warningActions = append(warningActions, "- open pull request for changes (if any)")
+			if mu.Options.Draft {
+				warningActions = append(warningActions, "- open draft pull request for changes (if any)")
+			} else {
+				warningActions = append(warningActions, "- open pull request for changes (if any)")
+			}
+		}
+		warningActions = append(warningActions, mu.Options.prMetadataActions()...)
+		if mu.Options.Cascade {
+			warningActions = append(warningActions, "- wait for pull requests to merge before syncing dependants")
+		}
+		if mu.Options.Pseudo && !mu.Options.Tag {
+			warningActions = append(warningActions, "- sync dependants to pseudo-versions of pushed commits (without tagging)")
 		}
 		if mu.Options.Tag {
 			if len(mu.Options.SetVersion) > 0 {
 				warningActions = append(warningActions, "- tag all dependencies "+mu.Options.SetVersion)
 			} else {
-				warningActions = append(warningActions, "- increment tag version (if updated)")
+				warningActions = append(warningActions, "- increment "+mu.Options.bumpLabel()+" tag version (if updated)")
+			}
+			if action := mu.Options.apiCheckAction(); len(action) > 0 {
+				warningActions = append(warningActions, action)
+			}
+			if action := mu.Options.changelogAction(); len(action) > 0 {
+				warningActions = append(warningActions, action)
+			}
+			if mu.Options.Release {
+				warningActions = append(warningActions, "- create a release for each new tag")
 			}
 		}
 
 		com.Println("\n" + strings.Join(warningActions, "\n  "))
 
+		if !ShowWarning("\nIs this ok?") {
+			cleanupStash(libs)
+			os.Exit(-1)
+		}
+	case "major":
+		com.Println("")
+		count := 0
+		for itr := fileHead; itr != nil; itr = itr.Next {
+			count++
+			if mu.targets[itr.File.Path] {
+				com.Println(strconv.Itoa(count) + ") " + itr.File.GetGoURL() + " (next major version)")
+			} else {
+				com.Println(strconv.Itoa(count) + ") " + itr.File.GetGoURL())
+			}
+		}
+
+		warningActions := []string{"\nMajor action will:"}
+		if mu.Options.Branch != "" {
+			warningActions = append(warningActions, "- checkout (or create) branch "+mu.Options.Branch)
+		}
+		warningActions = append(warningActions, "- update module paths and tag the next major version of marked libs")
+		warningActions = append(warningActions, "- rewrite imports and update mod files in dependants")
+		if mu.Options.PullRequest {
+			warningActions = append(warningActions, "- open pull request for changes (if any)")
+		}
+		warningActions = append(warningActions, mu.Options.prMetadataActions()...)
+		if mu.Options.Cascade {
+			warningActions = append(warningActions, "- wait for pull requests to merge before syncing dependants")
+		}
+
+		com.Println(strings.Join(warningActions, "\n  "))
+
+		if !ShowWarning("\nIs this ok?") {
+			cleanupStash(libs)
+			os.Exit(-1)
+		}
+	case "go-version":
+		com.Println("")
+		count := 0
+		for itr := fileHead; itr != nil; itr = itr.Next {
+			count++
+			com.Println(strconv.Itoa(count) + ") " + itr.File.GetGoURL())
+		}
+
+		warningActions := []string{"\nGo-version action will:"}
+		if mu.Options.Branch != "" {
+			warningActions = append(warningActions, "- checkout (or create) branch "+mu.Options.Branch)
+		}
+		if toolchain := mu.Options.toolchain(); len(toolchain) > 0 {
+			warningActions = append(warningActions, "- set go "+strings.TrimPrefix(mu.Options.GoVersion, "go")+" and toolchain "+toolchain+" directives (never lowering them)")
+		} else {
+			warningActions = append(warningActions, "- set go "+strings.TrimPrefix(mu.Options.GoVersion, "go")+" directive (never lowering it)")
+		}
+		if mu.Options.Commit {
+			warningActions = append(warningActions, "- tidy, build and test, then commit the change")
+			warningActions = append(warningActions, "- update mod files")
+			if mu.Options.PullRequest {
+				warningActions = append(warningActions, "- open pull request for changes (if any)")
+			}
+			warningActions = append(warningActions, mu.Options.prMetadataActions()...)
+			if mu.Options.Cascade {
+				warningActions = append(warningActions, "- wait for pull requests to merge before syncing dependants")
+			}
+			if mu.Options.Tag {
+				warningActions = append(warningActions, "- increment "+mu.Options.bumpLabel()+" tag version (if updated)")
+			}
+		} else {
+			// Syncing would reset the uncommitted go directive
+			warningActions = append(warningActions, "- tidy, build and test, leaving the change uncommitted")
+		}
+
+		com.Println(strings.Join(warningActions, "\n  "))
+
+		if !ShowWarning("\nIs this ok?") {
+			cleanupStash(libs)
+			os.Exit(-1)
+		}
+	case "retract", "deprecate":
+		com.Println("")
+		count := 0
+		for itr := fileHead; itr != nil; itr = itr.Next {
+			if mu.targets[itr.File.Path] {
+				count++
+				com.Println(strconv.Itoa(count) + ") " + itr.File.GetGoURL() + " (" + mu.Options.Action + ")")
+			} else if mu.Options.Dependants {
+				count++
+				com.Println(strconv.Itoa(count) + ") " + itr.File.GetGoURL())
+			}
+		}
+
+		warningActions := []string{"\nRetract action will:"}
+		if mu.Options.Action == "deprecate" {
+			warningActions[0] = "\nDeprecate action will:"
+		}
+		if mu.Options.Branch != "" {
+			warningActions = append(warningActions, "- checkout (or create) branch "+mu.Options.Branch)
+		}
+		if mu.Options.Action == "retract" {
+			warningActions = append(warningActions, "- retract "+mu.Options.Retract+" in marked libs")
+		} else {
+			warningActions = append(warningActions, "- deprecate marked libs")
+		}
+		warningActions = append(warningActions, "- tag a new version to publish the change")
+		if mu.Options.Dependants {
+			warningActions = append(warningActions, "- update mod files in dependants")
+		}
+		if mu.Options.PullRequest {
+			warningActions = append(warningActions, "- open pull request for changes (if any)")
+		}
+		warningActions = append(warningActions, mu.Options.prMetadataActions()...)
+		if mu.Options.Cascade {
+			warningActions = append(warningActions, "- wait for pull requests to merge before syncing dependants")
+		}
+
+		com.Println(strings.Join(warningActions, "\n  "))
+
+		if !ShowWarning("\nIs this ok?") {
+			cleanupStash(libs)
+			os.Exit(-1)
+		}
+	case "secret":
+		if mu.Options.SecretAction == "list" || mu.Options.SecretAction == "diff" {
+			// Read only
+			break
+		}
+
+		com.Println("")
+		for itr := fileHead; itr != nil; itr = itr.Next {
+			com.Println(itr.File.GetGoURL())
+		}
+
+		scope := mu.Options.secretScope()
+		if scope.Target == com.SecretOrg {
+			if mu.Options.SecretAction == "delete" {
+				com.Println("\nSecret action will delete organization secret " + mu.Options.secretName() + " from each lib's org")
+			} else {
+				com.Println("\nSecret action will set organization secret " + mu.Options.secretName() + " granting these libs access (repos already selected keep access)")
+			}
+		} else {
+			target := "repository"
+			if scope.Target == com.SecretEnv {
+				target = scope.Environment + " environment"
+			}
+
+			if mu.Options.SecretAction == "delete" {
+				com.Println("\nSecret action will delete " + target + " secret " + mu.Options.secretName() + " from each lib")
+			} else {
+				com.Println("\nSecret action will set " + target + " secret " + mu.Options.secretName() + " on each lib")
+			}
+		}
+
 		if !ShowWarning("\nIs this ok?") {
 			cleanupStash(libs)
 			os.Exit(-1)
@@ -172,8 +422,20 @@ func (mu *MU) perform() {
 		// No worries
 	}
 
+	if mu.Options.LocalProxy && !mu.useLocalProxy(fileHead) {
+		return
+	}
+
+	if mu.Options.Cascade && (mu.Options.Action == "sync" || mu.Options.Action == "upgrade" || mu.Options.Action == "major" || mu.Options.Action == "retract" || mu.Options.Action == "deprecate" || mu.Options.Action == "go-version") {
+		// Sync one dependency level at a time, waiting for PRs to merge in between
+		mu.cascade(fileHead)
+		mu.printNames(fileHead)
+		return
+	}
+
 	// Perform action on sorted libs
 	index := 0
+	prStatuses := make([][]PRStatus, mu.Stats.DepCount)
 	waiter := sizedwaitgroup.New(runtime.GOMAXPROCS(0))
 	for itr := fileHead; itr != nil; itr = itr.Next {
 		index++
@@ -250,10 +512,40 @@ func (mu *MU) perform() {
 				waiter.Done()
 			}(index, lib)
 			continue
+		case "prs":
+			waiter.Add()
+			go func(index int, lib Library) {
+				// Separate output
+				com.Println("")
+				com.Println("(", index, "/", mu.Stats.DepCount, ")", lib.File.Path)
+
+				// Each routine writes to its own index
+				prStatuses[index-1], _ = mu.prStatuses(lib)
+
+				waiter.Done()
+			}(index, lib)
+			continue
 		case "secret":
-			//mu.addSecret(lib)
-			println("Secrets unsupported without salt :(")
-			return
+			// Separate output
+			com.Println("")
+			com.Println("(", index, "/", mu.Stats.DepCount, ")", lib.File.Path)
+
+			mu.secret(lib)
+			continue
+		case "skew":
+			// Separate output
+			com.Println("")
+			com.Println("(", index, "/", mu.Stats.DepCount, ")", lib.File.Path)
+
+			mu.collectRequirements(lib)
+			continue
+		case "outdated":
+			// Separate output
+			com.Println("")
+			com.Println("(", index, "/", mu.Stats.DepCount, ")", lib.File.Path)
+
+			mu.outdated(lib, fileHead)
+			continue
 		}
 
 		// Separate output
@@ -261,63 +553,123 @@ func (mu *MU) perform() {
 		com.Println("(", index, "/", mu.Stats.DepCount, ")", itr.File.Path)
 
 		// Sync
-		if len(lib.File.Version) > 0 {
-			lib.File.Output("Already has version set: " + lib.File.Version)
-			continue
-		}
-
-		// Handle branching
-		mu.updateOrCreateBranch(lib)
+		mu.syncLib(lib, fileHead, true)
 
 		if closed {
 			// Stop execution and clean up
 			return
 		}
+	}
 
-		// Aggregate updated versions of previously parsed deps
-		lib.ModAddDeps(fileHead, false)
+	waiter.Wait()
 
-		mu.commit(lib)
+	if mu.Options.Action == "secret" {
+		mu.orgSecrets()
+	}
 
-		if closed {
-			// Stop execution and clean up
-			return
-		}
+	if mu.Options.Action == "skew" {
+		mu.Stats.Skew = mu.skewReport()
+	}
 
-		commitTitle, commitMessage := mu.getCommitDetails(lib)
-		mu.sync(lib, commitTitle, commitMessage)
+	for _, statuses := range prStatuses {
+		mu.Stats.PRStatuses = append(mu.Stats.PRStatuses, statuses...)
+	}
 
-		if closed {
-			// Stop execution and clean up
-			return
+	mu.printNames(fileHead)
+}
+
+// printNames prints the go urls of changed libs when output is name-only
+func (mu *MU) printNames(fileHead *sort.FileNode) {
+	if com.GetLogLevel() == com.NAMEONLY {
+		// Print names and quit
+		for fileItr := fileHead; fileItr != nil; fileItr = fileItr.Next {
+			if fileItr.File.Tagged || fileItr.File.Committed || fileItr.File.Updated || fileItr.File.PROpened || mu.Options.Action == "list" {
+				com.Outputln(com.NAMEONLY, fileItr.File.GetGoURL())
+			}
 		}
+	}
+}
 
-		// Create PR
-		mu.pullRequest(lib, mu.Options.Branch, commitTitle, commitMessage)
+// syncLib updates mod files for a lib, then commits, opens a PR and tags as configured
+func (mu *MU) syncLib(lib Library, fileHead *sort.FileNode, shouldTag bool) {
+	if mu.isSkippedDependant(lib) {
+		return
+	}
 
-		if closed {
-			// Stop execution and clean up
+	if lib.Policy().Exclude {
+		lib.File.Output("Excluded by " + PolicyFile + ". Skipping.")
+		return
+	}
+
+	if len(lib.File.Version) > 0 {
+		lib.File.Output("Already has version set: " + lib.File.Version)
+		return
+	}
+
+	// Handle branching
+	mu.updateOrCreateBranch(lib)
+
+	if closed {
+		// Stop execution and clean up
+		return
+	}
+
+	switch mu.Options.Action {
+	case "major":
+		// Commit module path and import changes before mod files are reset
+		mu.migrateMajor(&lib)
+	case "retract", "deprecate":
+		mu.updateModDirectives(lib)
+	case "go-version":
+		if !mu.setGoVersion(lib) {
+			// Leave libs unable to use the target version as is
+			mu.removeBranchIfUnused(lib)
 			return
 		}
 
-		mu.removeBranchIfUnused(lib)
-
-		if closed {
-			// Stop execution and clean up
+		if !mu.Options.Commit {
+			// Syncing would reset the uncommitted go directive
 			return
 		}
+	}
+
+	// Aggregate updated versions of previously parsed deps
+	lib.ModAddDeps(fileHead, false)
+	lib.upgrades = mu.upgrades
+	lib.pseudo, lib.localProxy = mu.allowPseudo()
+
+	mu.commit(lib)
 
-		mu.tag(lib)
+	if closed {
+		// Stop execution and clean up
+		return
 	}
 
-	waiter.Wait()
+	commitTitle, _ := mu.getCommitDetails(lib)
+	mu.sync(&lib, commitTitle)
 
-	if com.GetLogLevel() == com.NAMEONLY {
-		// Print names and quit
-		for fileItr := fileHead; fileItr != nil; fileItr = fileItr.Next {
-			if fileItr.File.Tagged || fileItr.File.Committed || fileItr.File.Updated || fileItr.File.PROpened || mu.Options.Action == "list" {
-				com.Outputln(com.NAMEONLY, fileItr.File.GetGoURL())
-			}
-		}
+	if closed {
+		// Stop execution and clean up
+		return
 	}
+
+	// Describe deps set after policy checks
+	_, commitMessage := mu.getCommitDetails(lib)
+
+	// Create PR
+	mu.pullRequest(lib, mu.Options.Branch, commitTitle, commitMessage)
+
+	if closed {
+		// Stop execution and clean up
+		return
+	}
+
+	mu.removeBranchIfUnused(lib)
+
+	if closed || !shouldTag {
+		// Stop execution and clean up
+		return
+	}
+
+	mu.tag(lib)
 }
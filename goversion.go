@@ -0,0 +1,120 @@
+package gomu
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+// setGoVersion updates the go and toolchain directives of a lib, then tidies, builds and tests the result. Commits if commit is set.
+// Returns false if the lib can not be updated to the target version
+func (mu *MU) setGoVersion(lib Library) bool {
+	target := strings.TrimPrefix(mu.Options.GoVersion, "go")
+	current, toolchain := lib.File.GoDirective()
+	if len(current) == 0 && len(lib.File.ModulePath()) == 0 {
+		lib.File.Output("No mod file found. Skipping.")
+		return false
+	}
+
+	if com.CompareGoVersions(current, target) > 0 {
+		lib.File.Output("Requires go " + current + ". Refusing to lower to " + target + " :(")
+		mu.skipGoVersion(lib, "requires go "+current)
+		return false
+	}
+
+	deps, err := lib.File.DepsRequiringGo(target)
+	if err != nil {
+		lib.File.Output("Unable to check dependency go versions :( " + err.Error())
+	}
+
+	if len(deps) > 0 {
+		for _, dep := range deps {
+			lib.File.Output(dep + ", newer than " + target + " :(")
+		}
+
+		revertModFiles(lib)
+		mu.skipGoVersion(lib, strings.Join(deps, ", "))
+		return false
+	}
+
+	newToolchain := mu.Options.toolchain()
+	switch {
+	case len(newToolchain) > 0 && len(toolchain) > 0 && com.CompareGoVersions(toolchain, newToolchain) >= 0:
+		// Keep newer toolchains
+		newToolchain = ""
+	case len(newToolchain) == 0 && len(toolchain) > 0 && com.CompareGoVersions(toolchain, target) < 0:
+		// Toolchain may not be older than the go directive
+		newToolchain = "none"
+	}
+
+	if current == target && len(newToolchain) == 0 {
+		lib.File.Output("Already go " + target + "!")
+		return true
+	}
+
+	if err = lib.File.SetGoDirective(target, newToolchain); err != nil {
+		lib.File.Output("Failed to update go directive :(")
+		mu.Errors = append(mu.Errors, err)
+		return false
+	}
+
+	lib.File.Output("Updated go directive to " + target + ". Building and testing...")
+	failure := ""
+	if err = lib.ModTidy(); err != nil {
+		failure = "mod tidy failed"
+	} else if err = lib.ModVendor(); err != nil {
+		failure = "mod vendor failed"
+	} else if err = lib.File.RunCmd("go", "build", "./..."); err != nil {
+		failure = "build failed"
+	} else if err = lib.File.RunCmd("go", "test", "./..."); err != nil {
+		failure = "tests failed"
+	}
+
+	if len(failure) > 0 {
+		lib.File.Output("Go " + target + " " + failure + " :(")
+		revertModFiles(lib)
+		mu.skipGoVersion(lib, failure+" with go "+target)
+		return false
+	}
+
+	update := "go " + current + " -> " + target
+	if !mu.Options.Commit {
+		// Left in the working tree for review
+		lib.File.Output("Go " + target + " builds and tests! Not committing.")
+		mu.Stats.GoVersionCount++
+		mu.Stats.GoVersionOutput += strconv.Itoa(mu.Stats.GoVersionCount) + ") " + lib.File.Path + " - " + update + " (uncommitted)\n"
+		return true
+	}
+
+	title := "gomu: Update go directive to " + target
+	if err = lib.AddModFiles(); err == nil {
+		err = lib.File.Commit(title)
+	}
+
+	if err != nil {
+		lib.File.Output("Failed to commit go directive :(")
+		return false
+	}
+
+	lib.File.Committed = true
+	mu.Stats.GoVersionCount++
+	mu.Stats.GoVersionOutput += strconv.Itoa(mu.Stats.GoVersionCount) + ") " + lib.File.Path + " - " + update + "\n"
+	return true
+}
+
+// skipGoVersion records a lib that can not be updated to the target go version
+func (mu *MU) skipGoVersion(lib Library, reason string) {
+	mu.Stats.GoSkippedCount++
+	mu.Stats.GoSkippedOutput += strconv.Itoa(mu.Stats.GoSkippedCount) + ") " + lib.File.Path + " - " + reason + "\n"
+}
+
+// revertModFiles discards uncommitted changes to go.mod, go.sum and the vendor directory
+func revertModFiles(lib Library) {
+	lib.File.RunCmd("git", "checkout", "go.mod")
+	lib.File.RunCmd("git", "checkout", "go.sum")
+	if lib.File.IsVendored() {
+		lib.File.RunCmd("git", "checkout", "vendor")
+		lib.File.RunCmd("git", "clean", "-fd", "vendor")
+	}
+}
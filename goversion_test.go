@@ -0,0 +1,52 @@
+package gomu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetGoVersion(t *testing.T) {
+	t.Setenv("GOTOOLCHAIN", "local")
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	tests := []struct {
+		name       string
+		commit     bool
+		wantOutput string
+	}{
+		{"commit", true, "go 1.17 -> 1.21\n"},
+		{"no commit", false, "go 1.17 -> 1.21 (uncommitted)\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lib := testTaggedLib(t, "github.com/org/repo", map[string]string{"repo.go": "package repo\n"})
+			head := testGit(t, lib.File.Path, "rev-parse", "HEAD")
+
+			var mu MU
+			mu.Options.GoVersion = "1.21"
+			mu.Options.Commit = test.commit
+			if !mu.setGoVersion(lib) {
+				t.Fatalf("setGoVersion() = false, want true")
+			}
+
+			if directive, _ := lib.File.GoDirective(); directive != "1.21" {
+				t.Errorf("go directive = %s, want 1.21", directive)
+			}
+
+			// Only commits when commit is set
+			committed := testGit(t, lib.File.Path, "rev-parse", "HEAD") != head
+			if committed != test.commit || lib.File.Committed != test.commit {
+				t.Errorf("committed = %v, want %v", committed, test.commit)
+			}
+
+			if status := testGit(t, lib.File.Path, "status", "--porcelain"); (len(status) == 0) != test.commit {
+				t.Errorf("git status = %q, want changes left uncommitted %v", status, !test.commit)
+			}
+
+			if !strings.HasSuffix(mu.Stats.GoVersionOutput, test.wantOutput) {
+				t.Errorf("GoVersionOutput = %q, want %q", mu.Stats.GoVersionOutput, test.wantOutput)
+			}
+		})
+	}
+}
@@ -10,6 +10,20 @@ type Library struct {
 	File *com.FileWrapper
 
 	updatedDeps *sort.FileNode
+
+	// Deps set in go.mod, described in commit messages
+	setDeps []string
+
+	// Upgrades not allowed by the lib's policy
+	skippedDeps []string
+
+	// Untagged deps may be set to pseudo-versions, unpushed ones only when resolved through the local proxy
+	pseudo     bool
+	localProxy bool
+
+	// External module versions to set, and those set
+	upgrades []moduleUpgrade
+	upgraded []string
 }
 
 // LibraryFromPath returns a library reference for a filepath
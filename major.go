@@ -0,0 +1,121 @@
+package gomu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hatchify/mod-utils/com"
+	"github.com/hatchify/mod-utils/sort"
+)
+
+// majorMigration represents a lib moved to a new major version module path
+type majorMigration struct {
+	File    *com.FileWrapper
+	OldPath string
+	NewPath string
+}
+
+// majorRenames returns old -> new module paths of all libs migrated so far
+func (mu *MU) majorRenames() (renames map[string]string) {
+	renames = make(map[string]string)
+	for _, migration := range mu.majorMigrations {
+		if migration.OldPath != migration.NewPath {
+			renames[migration.OldPath] = migration.NewPath
+		}
+	}
+
+	return
+}
+
+// migrateMajor updates the module path of a target lib, then rewrites imports of migrated libs and commits the result
+func (mu *MU) migrateMajor(lib *Library) {
+	var title string
+	if mu.targets[lib.File.Path] {
+		oldPath := lib.File.ModulePath()
+		if len(oldPath) == 0 {
+			lib.File.Output("No mod file found. Skipping.")
+			return
+		}
+
+		latest, found := lib.File.LatestVersion()
+		if !found {
+			lib.File.Output("No tag set. Unable to determine next major version :(")
+			mu.Errors = append(mu.Errors, fmt.Errorf("unable to migrate %s: no tag set", oldPath))
+			return
+		}
+
+		next := latest.Major + 1
+		newPath := com.MajorPath(oldPath, next)
+		if newPath != oldPath {
+			if err := lib.File.SetModulePath(newPath); err != nil {
+				lib.File.Output("Failed to update module path :(")
+				mu.Errors = append(mu.Errors, err)
+				return
+			}
+
+			lib.File.Output("Updated module path to " + newPath)
+		}
+
+		mu.majorMigrations = append(mu.majorMigrations, majorMigration{File: lib.File, OldPath: oldPath, NewPath: newPath})
+		title = "gomu: Migrate to v" + strconv.Itoa(next) + " (" + newPath + ")"
+	}
+
+	renames := mu.majorRenames()
+	changed, err := lib.File.RewriteImports(renames)
+	if err != nil {
+		lib.File.Output("Failed to rewrite imports :( " + err.Error())
+		mu.Errors = append(mu.Errors, err)
+		return
+	}
+
+	var updated []string
+	for _, migration := range mu.majorMigrations {
+		if migration.File.Path == lib.File.Path || !lib.File.RequiresModule(migration.OldPath) {
+			continue
+		}
+
+		// Require the new major version when syncing mod files
+		var dep sort.FileNode
+		dep.File = migration.File
+		lib.AddDep(&dep)
+		updated = append(updated, migration.NewPath)
+	}
+
+	if changed > 0 {
+		lib.File.Output("Rewrote imports in " + strconv.Itoa(changed) + " file(s)")
+	}
+
+	if len(title) == 0 {
+		if changed == 0 {
+			// Nothing to migrate
+			return
+		}
+
+		title = "gomu: Update imports to " + strings.Join(updated, ", ")
+	}
+
+	if err = lib.File.Add("."); err == nil {
+		err = lib.File.Commit(title)
+	}
+
+	if err != nil {
+		lib.File.Output("Failed to commit major version migration :(")
+		return
+	}
+
+	lib.File.Committed = true
+	mu.Stats.MajorCount++
+	mu.Stats.MajorOutput += strconv.Itoa(mu.Stats.MajorCount) + ") " + lib.File.Path + " - " + strings.TrimPrefix(title, "gomu: ") + "\n"
+}
+
+// isMajorMigration returns true if the lib was moved to a new major version this session
+func (mu *MU) isMajorMigration(lib Library) bool {
+	for _, migration := range mu.majorMigrations {
+		if migration.File.Path == lib.File.Path {
+			return true
+		}
+	}
+
+	return false
+}
@@ -6,12 +6,14 @@ import (
 	"os/exec"
 	"path"
 
+	"github.com/hatchify/mod-utils/com"
 	"github.com/hatchify/mod-utils/sort"
 )
 
 // CleanModCache calls go clean --modcache from calling directory. No context necessary
 func CleanModCache() error {
 	cmd := exec.Command("go", "clean", "--modcache")
+	cmd.Env = com.GoEnviron()
 	return cmd.Run()
 }
 
@@ -25,6 +27,25 @@ func (lib *Library) ModTidy() error {
 	return lib.File.RunCmd("go", "mod", "tidy")
 }
 
+// ModVendor calls go mod vendor on a given lib if it vendors dependencies
+func (lib *Library) ModVendor() error {
+	if !lib.File.IsVendored() {
+		return nil
+	}
+
+	lib.File.Output("Updating vendor directory...")
+	return lib.File.RunCmd("go", "mod", "vendor")
+}
+
+// AddModFiles stages go.mod, go.sum and the vendor directory (if vendored)
+func (lib *Library) AddModFiles() (err error) {
+	if err = lib.File.Add("go.*"); err != nil || !lib.File.IsVendored() {
+		return
+	}
+
+	return lib.File.Add("vendor")
+}
+
 // ModClearFiles calls rm go.mod and rm go.sum, returning the success of both commands
 func (lib *Library) ModClearFiles() (hasModFile, hasSumFile bool) {
 	if lib.File.RunCmd("rm", "go.mod") == nil {
@@ -40,12 +61,18 @@ func (lib *Library) ModClearFiles() (hasModFile, hasSumFile bool) {
 
 // ModAddDeps adds a dep@version to go.mod to force-update or force-downgrade any deps in the filtered chain
 func (lib *Library) ModAddDeps(listHead *sort.FileNode, shouldForce bool) {
+	policy := lib.Policy()
 	for itr := listHead; itr != nil && itr.File.Path != lib.File.Path; itr = itr.Next {
 		// File has update if it was changed or needs to be explicitly set
 		var hasUpdate = (itr.File.Updated || itr.File.Tagged || itr.File.Committed || len(itr.File.Version) != 0)
 
 		// File should update if forced and direct or indirect, or if has update and direct
 		if (shouldForce && lib.File.DependsOn(itr.File)) || (hasUpdate && lib.File.DirectlyImports(itr.File)) {
+			if policy.dep(itr.File.GetGoURL()).Exclude {
+				lib.skipDep(itr.File.GetGoURL(), "", "excluded by "+PolicyFile)
+				continue
+			}
+
 			// Create new node to add to independent list on lib with same file ref
 			var node sort.FileNode
 			node.File = itr.File
@@ -54,17 +81,34 @@ func (lib *Library) ModAddDeps(listHead *sort.FileNode, shouldForce bool) {
 	}
 }
 
-// ModSetDeps adds a dep@version to go.mod to force-update or force-downgrade any deps in the filtered chain
+// ModSetDeps adds a dep@version to go.mod to force-update or force-downgrade any deps in the filtered chain, unless not allowed by the lib's policy
 func (lib *Library) ModSetDeps() (err error) {
+	policy := lib.Policy()
+
 	// Iterate through dep chain
 	for itr := lib.updatedDeps; itr != nil; itr = itr.Next {
 		if len(itr.File.Version) == 0 {
 			tempLib := Library{}
 			tempLib.File = itr.File
 			itr.File.Version = tempLib.GetLatestTag()
+
+			if len(itr.File.Version) == 0 && lib.pseudo {
+				if lib.localProxy || itr.File.IsPushed() {
+					// Untagged deps are required at their current commit
+					itr.File.Version = tempLib.GetPseudoVersion()
+				} else {
+					// Dependants could not resolve the commit
+					lib.File.Output("Unable to set " + itr.File.GetGoURL() + " to a pseudo-version :( changes not pushed")
+				}
+			}
 		}
 
 		url := itr.File.GetGoURL()
+		if reason := lib.skipReason(policy, url, itr.File.Version); len(reason) > 0 {
+			// Keep the currently required version
+			lib.skipDep(url, itr.File.Version, reason)
+			continue
+		}
 
 		// Get dep @ version (-d avoids building)
 		if lib.File.RunCmd("go", "get", "-d", url+"@"+itr.File.Version) == nil {
@@ -73,6 +117,12 @@ func (lib *Library) ModSetDeps() (err error) {
 			} else {
 				lib.File.Output("Set " + url + " @ " + itr.File.Version)
 			}
+
+			if itr.File.Updated {
+				lib.setDeps = append(lib.setDeps, "Updated "+url+"@"+itr.File.Version)
+			} else {
+				lib.setDeps = append(lib.setDeps, "Set "+url+"@"+itr.File.Version)
+			}
 		} else {
 			lib.File.Output("Error: Failed to get " + url + " @ " + itr.File.Version)
 			err = fmt.Errorf("Unable to set dependency: " + url + " @ " + itr.File.Version)
@@ -99,7 +149,13 @@ func (lib *Library) ModReplaceLocal() (updated bool) {
 		updated = lib.AppendToModfile("\n\n// Replace Local Deps\n\n" + localSuffix)
 
 		lib.File.RunCmd("rm", "go.sum")
+
 		lib.ModTidy()
+
+		// Keeps -mod=vendor builds consistent. Reset restores the committed vendor directory
+		if err := lib.ModVendor(); err != nil {
+			lib.File.Output("Mod vendor failed :(")
+		}
 	}
 	return
 }
@@ -135,6 +191,9 @@ func (lib *Library) ModDeploy(tag, commitMessage string) (deployed bool) {
 
 	// Ignore changes to go mod files (prevents committing local replacements)
 	lib.File.Reset("go.*")
+	if lib.File.IsVendored() {
+		lib.File.Reset("vendor")
+	}
 
 	message := ""
 	if len(tag) == 0 {
@@ -168,7 +227,7 @@ func (lib *Library) ModDeploy(tag, commitMessage string) (deployed bool) {
 }
 
 // ModUpdate will refresh the current dir to master, reset mod files and push changes if there are any
-func (lib *Library) ModUpdate(branch, commitMessage string) (err error) {
+func (lib *Library) ModUpdate(branch, commitTitle string) (err error) {
 	lib.File.Output("Checking deps...")
 	// Remove go.mod, ignore lib if not found (not a mod tracked lib)
 	if lib.File.RunCmd("rm", "go.mod") != nil {
@@ -179,6 +238,7 @@ func (lib *Library) ModUpdate(branch, commitMessage string) (err error) {
 	// Reset mod files, or initialize if needed
 	lib.File.RunCmd("git", "checkout", "go.mod")
 	lib.ModInit()
+	previous := lib.requiredVersions()
 
 	// Remove go sum to prevent mess from adding up
 	if lib.File.RunCmd("rm", "go.sum") != nil {
@@ -189,17 +249,31 @@ func (lib *Library) ModUpdate(branch, commitMessage string) (err error) {
 	// Set versions from previous libs in chain
 	lib.ModSetDeps()
 
+	// Set external module versions
+	lib.ModSetUpgrades()
+
 	if err = lib.ModTidy(); err != nil {
 		lib.File.Output("Mod tidy failed :(")
 		return
 	}
 
-	if err = lib.File.Add("go.*"); err != nil {
+	if err = lib.checkTidiedDeps(lib.Policy(), previous); err != nil {
+		// Don't commit versions the policy doesn't allow
+		revertModFiles(*lib)
+		return
+	}
+
+	if err = lib.ModVendor(); err != nil {
+		lib.File.Output("Mod vendor failed :(")
+		return
+	}
+
+	if err = lib.AddModFiles(); err != nil {
 		lib.File.Output("Git add failed :(")
 		return
 	}
 
-	if err = lib.File.Commit(commitMessage); err == nil {
+	if err = lib.File.Commit(commitTitle + "\n" + lib.commitDetails()); err == nil {
 		lib.File.Output("Updating mod files...")
 	} else {
 		lib.File.Output("Deps up to date!")
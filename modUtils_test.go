@@ -0,0 +1,123 @@
+package gomu
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/hatchify/mod-utils/com"
+	"github.com/hatchify/mod-utils/sort"
+)
+
+const testVendoredModFile = "module github.com/org/lib\n\ngo 1.17\n\nrequire github.com/x/a v1.2.0\n"
+
+// testVendoredLib returns a committed lib importing github.com/x/a from the test proxy, vendored if vendored is set
+func testVendoredLib(t *testing.T, dir string, vendored bool) Library {
+	t.Helper()
+	testGitIdentity(t)
+
+	testFiles(t, dir, map[string]string{
+		"go.mod": testVendoredModFile,
+		"lib.go": "package lib\n\nimport _ \"github.com/x/a\"\n",
+	})
+
+	lib := Library{File: &com.FileWrapper{Path: dir}}
+	if err := lib.ModTidy(); err != nil {
+		t.Fatal(err)
+	}
+
+	if vendored {
+		if err := lib.File.RunCmd("go", "mod", "vendor"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testGit(t, dir, "init", "-q")
+	testGit(t, dir, "add", "-A")
+	testGit(t, dir, "commit", "-q", "-m", "Initial commit")
+	return lib
+}
+
+// readTestFile returns the content of a file in dir, or empty if it doesn't exist
+func readTestFile(dir, name string) string {
+	data, _ := ioutil.ReadFile(path.Join(dir, name))
+	return string(data)
+}
+
+func TestModVendor(t *testing.T) {
+	testFileProxy(t, "github.com/x/a", "v1.2.0", "v1.3.0")
+
+	tests := []struct {
+		name        string
+		vendored    bool
+		wantModules string
+		wantStaged  []string
+	}{
+		{"not vendored", false, "", []string{"go.mod", "go.sum"}},
+		{"vendored", true, "# github.com/x/a v1.3.0", []string{"go.mod", "go.sum", "vendor/modules.txt"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lib := testVendoredLib(t, t.TempDir(), test.vendored)
+			if lib.File.IsVendored() != test.vendored {
+				t.Fatalf("IsVendored() = %v, want %v", lib.File.IsVendored(), test.vendored)
+			}
+
+			if err := lib.File.RunCmd("go", "get", "github.com/x/a@v1.3.0"); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := lib.ModVendor(); err != nil {
+				t.Fatalf("ModVendor() = %v", err)
+			}
+
+			// Only vendored libs get a vendor directory
+			modules := readTestFile(lib.File.Path, "vendor/modules.txt")
+			if !strings.Contains(modules, test.wantModules) || (len(test.wantModules) == 0) != (len(modules) == 0) {
+				t.Errorf("vendor/modules.txt = %q, want %q", modules, test.wantModules)
+			}
+
+			if err := lib.AddModFiles(); err != nil {
+				t.Fatalf("AddModFiles() = %v", err)
+			}
+
+			staged := testGit(t, lib.File.Path, "diff", "--cached", "--name-only")
+			if staged != strings.Join(test.wantStaged, "\n") {
+				t.Errorf("AddModFiles() staged %q, want %q", staged, test.wantStaged)
+			}
+		})
+	}
+}
+
+func TestModReplaceLocal(t *testing.T) {
+	testFileProxy(t, "github.com/x/a", "v1.2.0")
+
+	root := path.Join(t.TempDir(), "go", "src", "github.com")
+	dep := path.Join(root, "x", "a")
+	testFiles(t, dep, map[string]string{
+		"go.mod": "module github.com/x/a\n\ngo 1.17\n",
+		"a.go":   "package a\n\n// Local is only in the local checkout\nconst Local = true\n",
+	})
+
+	lib := testVendoredLib(t, path.Join(root, "org", "lib"), true)
+	testFiles(t, lib.File.Path, map[string]string{"lib.go": "package lib\n\nimport \"github.com/x/a\"\n\nvar _ = a.Local\n"})
+
+	lib.updatedDeps = &sort.FileNode{File: &com.FileWrapper{Path: dep}}
+	if !lib.ModReplaceLocal() {
+		t.Fatalf("ModReplaceLocal() = false, want true")
+	}
+
+	// Vendored builds use the local replacement
+	if modules := readTestFile(lib.File.Path, "vendor/modules.txt"); !strings.Contains(modules, "# github.com/x/a v1.2.0 => "+dep) {
+		t.Errorf("vendor/modules.txt = %q, want the local replacement", modules)
+	}
+
+	cmd := exec.Command("go", "build", "-mod=vendor", "./...")
+	cmd.Dir = lib.File.Path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go build -mod=vendor: %v\n%s", err, output)
+	}
+}
@@ -1,6 +1,9 @@
 package gomu
 
 import (
+	"fmt"
+	"path"
+	"regexp"
 	"strings"
 
 	"github.com/hatchify/mod-utils/com"
@@ -18,14 +21,68 @@ type Options struct {
 	PullRequest bool   `json:"createPR"`
 	Tag         bool   `json:"shouldTag"`
 	SetVersion  string `json:"setVersion"`
+	Pseudo      bool   `json:"pseudo"`     // Sync dependants to pseudo-versions of pushed commits when not tagging
+	Bump        string `json:"bump"`       // major, minor, patch or pre. Inferred from conventional commits when empty
+	Prerelease  string `json:"prerelease"` // Pre-release identifier (rc, beta, etc)
+	APICheck    string `json:"apiCheck"`   // major (force bump) or abort when exported api changes are incompatible with the bump
+
+	Changelog     bool `json:"changelog"`     // Annotate tags with a changelog of commits since the previous tag
+	ChangelogFile bool `json:"changelogFile"` // Also commit the changelog to CHANGELOG.md before tagging
+	Release       bool `json:"release"`       // Create a forge release with release notes for each new tag
+
+	Sign          bool   `json:"sign"`          // Sign commits and tags
+	SigningKey    string `json:"signingKey"`    // Defaults to git config user.signingkey
+	SigningFormat string `json:"signingFormat"` // openpgp, ssh or x509. Defaults to git config gpg.format
+	AuthorName    string `json:"authorName"`    // Bot identity for gomu commits and tags
+	AuthorEmail   string `json:"authorEmail"`
+
+	Upgrades sort.StringArray `json:"upgrades"` // External module@version (or @latest) to set in every lib requiring it
+
+	GoVersion string `json:"goVersion"` // Target go directive of the go-version action
+	Toolchain string `json:"toolchain"` // Toolchain directive set with the go directive. Defaults to the target for release versions (1.22.3)
+
+	Retract    string `json:"retract"`    // Version or range ([v1.0.0, v1.0.5]) to retract
+	Rationale  string `json:"rationale"`  // Retraction rationale or deprecation message
+	Dependants bool   `json:"dependants"` // Also sync dependants of retracted or deprecated libs
+
+	Labels      sort.StringArray `json:"labels"`
+	Reviewers   sort.StringArray `json:"reviewers"`
+	Assignees   sort.StringArray `json:"assignees"`
+	Draft       bool             `json:"draft"`
+	AutoMerge   bool             `json:"autoMerge"`
+	MergeMethod string           `json:"mergeMethod"`
+
+	Cascade        bool   `json:"cascade"`
+	CascadeTimeout int    `json:"cascadeTimeout"` // Minutes to wait for each PR to merge
+	PollInterval   int    `json:"pollInterval"`   // Seconds between PR status checks
+	CascadeFile    string `json:"cascadeFile"`
 
 	SourcePath string `json:"source,-"` // Not supported from server
 
+	Proxy         bool   `json:"proxy"`         // Also compare requirements against the module proxy
+	OutputFormat  string `json:"outputFormat"`  // table (default) or json
+	SkewThreshold int    `json:"skewThreshold"` // Highlight modules with more distinct versions than this (default 1)
+
+	GoPrivate   string `json:"goPrivate"` // Go env of spawned go commands, empty values keep the user's go env. Self-hosted forge hosts are added to GOPRIVATE
+	GoProxy     string `json:"goProxy"`
+	GoNoSumDB   string `json:"goNoSumDB"`
+	GoFlags     string `json:"goFlags"`
+	GoToolchain string `json:"goToolchain"`
+
+	ProxyAddress string `json:"proxyAddress"` // serve-proxy listen address (default localhost:8421)
+	LocalProxy   bool   `json:"localProxy"`   // Resolve local libs through a module proxy serving their repos, without pushing tags
+
+	SecretAction      string `json:"secretAction"`      // set (default), list, delete or diff
+	SecretName        string `json:"secretName"`        // Defaults to the source file name
+	SecretTarget      string `json:"secretTarget"`      // repo (default), org or env
+	SecretEnvironment string `json:"secretEnvironment"` // Deployment environment for env secrets
+
 	DirectImport       bool             `json:"direct"`
 	TargetDirectories  sort.StringArray `json:"searchLibs"` // Not supported from server
 	FilterDependencies sort.StringArray `json:"syncLibs"`
 
-	LogLevel com.LogLevel
+	LogLevel       com.LogLevel
+	NonInteractive bool `json:"nonInteractive"`
 }
 
 // New returns new Mod Utils struct
@@ -36,6 +93,84 @@ func New(options Options) *MU {
 	return &mu
 }
 
+// secretNamePattern matches valid actions secret names
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate returns an error for option values the action can't use
+func (o *Options) Validate() (err error) {
+	switch o.Bump {
+	case "", "major", "minor", "patch", "pre":
+	default:
+		return fmt.Errorf("unknown bump %q (major, minor, patch or pre)", o.Bump)
+	}
+
+	switch o.APICheck {
+	case "", "major", "abort":
+	default:
+		return fmt.Errorf("unknown api check %q (major or abort)", o.APICheck)
+	}
+
+	switch o.Action {
+	case "secret":
+		return o.validateSecret()
+	case "retract", "deprecate":
+		return o.validateDirective()
+	}
+
+	return
+}
+
+// validateDirective checks the retracted versions and rationale before any go.mod is edited
+func (o *Options) validateDirective() (err error) {
+	if o.Action == "retract" {
+		if len(strings.TrimSpace(o.Retract)) == 0 {
+			return fmt.Errorf("retract action requires a version or range")
+		}
+
+		if _, err = com.RetractRange(o.Retract); err != nil {
+			return
+		}
+	}
+
+	if len(strings.TrimSpace(o.Rationale)) == 0 {
+		return fmt.Errorf("%s action requires a rationale", o.Action)
+	}
+
+	return
+}
+
+// validateSecret checks the secret action, target and name before anything is sent
+func (o *Options) validateSecret() (err error) {
+	switch o.SecretAction {
+	case "", "set":
+		if len(o.SourcePath) == 0 {
+			return fmt.Errorf("secret action requires a source file")
+		}
+	case "delete", "diff":
+	case "list":
+		// No secret to name
+		return
+	default:
+		return fmt.Errorf("unknown secret action %q (set, list, delete or diff)", o.SecretAction)
+	}
+
+	switch o.SecretTarget {
+	case "", com.SecretRepo, com.SecretOrg:
+	case com.SecretEnv:
+		if len(o.SecretEnvironment) == 0 {
+			return fmt.Errorf("environment secrets require an environment")
+		}
+	default:
+		return fmt.Errorf("unknown secret target %q (repo, org or env)", o.SecretTarget)
+	}
+
+	if name := o.secretName(); !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q (letters, digits and underscores only)", name)
+	}
+
+	return
+}
+
 // Format will wrap options data into a printable output string
 func (o *Options) Format() (output string) {
 	warningActions := []string{"Sync action will:"}
@@ -43,23 +178,219 @@ func (o *Options) Format() (output string) {
 		warningActions = append(warningActions, "- checkout (or create) branch "+o.Branch)
 	}
 	warningActions = append(warningActions, "- update mod files")
+	if o.LocalProxy {
+		warningActions = append(warningActions, "- resolve local libs through a module proxy serving their repos")
+	}
 	if o.Commit {
 		warningActions = append(warningActions, "- commit local changes (if any)")
 	}
+	warningActions = append(warningActions, o.signingActions()...)
 	if o.PullRequest {
-		warningActions = append(warningActions, "- open pull request for changes (if any)")
+		if o.Draft {
+			warningActions = append(warningActions, "- open draft pull request for changes (if any)")
+		} else {
+			warningActions = append(warningActions, "- open pull request for changes (if any)")
+		}
+	}
+	if o.Pseudo && !o.Tag {
+		warningActions = append(warningActions, "- sync dependants to pseudo-versions of pushed commits (without tagging)")
 	}
 	if o.Tag {
 		if len(o.SetVersion) > 0 {
 			warningActions = append(warningActions, "- tag all dependencies "+o.SetVersion)
 		} else {
-			warningActions = append(warningActions, "- increment tag version (if updated)")
+			warningActions = append(warningActions, "- increment "+o.bumpLabel()+" tag version (if updated)")
+		}
+		if action := o.apiCheckAction(); len(action) > 0 {
+			warningActions = append(warningActions, action)
+		}
+		if action := o.changelogAction(); len(action) > 0 {
+			warningActions = append(warningActions, action)
+		}
+		if o.Release {
+			warningActions = append(warningActions, "- create a release for each new tag")
 		}
 	}
 
+	warningActions = append(warningActions, o.prMetadataActions()...)
+	if o.Cascade {
+		warningActions = append(warningActions, "- wait for pull requests to merge before syncing dependants")
+	}
+
 	msg := strings.Join(warningActions, "\n  ")
 	msg += "\n\nOn repositories: " + o.FilterDependencies.String()
 	msg += "\nIn directories: " + o.TargetDirectories.String()
 
 	return msg
 }
+
+// Signing returns the signing settings and identity for commits and tags
+func (o *Options) Signing() com.Signing {
+	return com.Signing{
+		Sign:   o.Sign,
+		Key:    o.SigningKey,
+		Format: o.SigningFormat,
+		Name:   o.AuthorName,
+		Email:  o.AuthorEmail,
+	}
+}
+
+// GoEnv returns the environment of spawned go commands, with self-hosted forge hosts of the sorted libs added to GOPRIVATE
+func (o *Options) GoEnv(fileHead *sort.FileNode) com.GoEnv {
+	env := com.GoEnv{
+		Private:   o.GoPrivate,
+		Proxy:     o.GoProxy,
+		NoSumDB:   o.GoNoSumDB,
+		Flags:     o.GoFlags,
+		Toolchain: o.GoToolchain,
+	}
+
+	env.AddPrivate(com.PrivateHosts(gitHosts(fileHead))...)
+	return env
+}
+
+// PRMetadata returns the pull request metadata to apply after a PR is created
+func (o *Options) PRMetadata() com.PRMetadata {
+	return com.PRMetadata{
+		Labels:      o.Labels,
+		Reviewers:   o.Reviewers,
+		Assignees:   o.Assignees,
+		AutoMerge:   o.AutoMerge,
+		MergeMethod: o.MergeMethod,
+	}
+}
+
+// prMetadataActions returns warning lines describing pull request metadata
+func (o *Options) prMetadataActions() (actions []string) {
+	if !o.PullRequest {
+		return
+	}
+
+	if len(o.Labels) > 0 {
+		actions = append(actions, "- label pull requests"+o.Labels.String())
+	}
+	if len(o.Reviewers) > 0 {
+		actions = append(actions, "- request review from"+o.Reviewers.String())
+	}
+	if len(o.Assignees) > 0 {
+		actions = append(actions, "- assign pull requests to"+o.Assignees.String())
+	}
+	if o.AutoMerge {
+		actions = append(actions, "- enable auto-merge when checks pass")
+	}
+
+	return
+}
+
+// forgeFeatures returns the forge features used by the action
+func (o *Options) forgeFeatures() (features []string) {
+	if o.PullRequest || o.Action == "prs" {
+		features = append(features, com.FeaturePullRequests)
+	}
+	if o.Release {
+		features = append(features, com.FeatureReleases)
+	}
+	if o.Action == "secret" {
+		features = append(features, com.FeatureSecrets)
+	}
+
+	return
+}
+
+// secretName returns the configured secret name, or the name of the source file
+func (o *Options) secretName() string {
+	if len(o.SecretName) > 0 {
+		return o.SecretName
+	}
+
+	_, name := path.Split(o.SourcePath)
+	return name
+}
+
+// secretScope returns where secrets are stored for the secret action
+func (o *Options) secretScope() com.SecretScope {
+	return com.SecretScope{Target: o.SecretTarget, Environment: o.SecretEnvironment}
+}
+
+// bumpLabel returns a readable description of the tag increment
+func (o *Options) bumpLabel() string {
+	level := o.Bump
+	if len(level) == 0 {
+		level = "inferred"
+	}
+
+	if len(o.Prerelease) > 0 {
+		return level + " " + o.Prerelease
+	}
+
+	return level
+}
+
+// apiCheckAction returns a warning line describing the api compatibility check
+func (o *Options) apiCheckAction() string {
+	switch o.APICheck {
+	case "major":
+		return "- force larger tag version for incompatible api changes (new majors still require the major action)"
+	case "abort":
+		return "- skip tag for incompatible api changes"
+	}
+
+	return ""
+}
+
+// changelogAction returns a warning line describing changelog generation
+func (o *Options) changelogAction() string {
+	switch {
+	case o.Changelog && o.ChangelogFile:
+		return "- commit changelog to " + com.ChangelogFile + " and annotate tags"
+	case o.Changelog:
+		return "- annotate tags with changelog"
+	}
+
+	return ""
+}
+
+// signingActions returns warning lines describing commit signing and identity
+func (o *Options) signingActions() (actions []string) {
+	if o.Sign {
+		actions = append(actions, "- sign commits and tags")
+	}
+	if len(o.AuthorEmail) > 0 {
+		actions = append(actions, "- commit as "+strings.TrimSpace(o.AuthorName+" <"+o.AuthorEmail+">"))
+	} else if len(o.AuthorName) > 0 {
+		actions = append(actions, "- commit as "+o.AuthorName)
+	}
+
+	return
+}
+
+// skewThreshold returns the number of distinct versions a module may have before it is highlighted
+func (o *Options) skewThreshold() int {
+	if o.SkewThreshold > 0 {
+		return o.SkewThreshold
+	}
+
+	return 1
+}
+
+// toolchain returns the toolchain directive to set with the target go version, or empty to keep the current directive
+func (o *Options) toolchain() string {
+	if len(o.Toolchain) > 0 {
+		return o.Toolchain
+	}
+
+	if com.IsGoRelease(o.GoVersion) {
+		return "go" + strings.TrimPrefix(o.GoVersion, "go")
+	}
+
+	return ""
+}
+
+// proxyAddress returns the address serve-proxy listens on
+func (o *Options) proxyAddress() string {
+	if len(o.ProxyAddress) > 0 {
+		return o.ProxyAddress
+	}
+
+	return DefaultProxyAddress
+}
@@ -0,0 +1,29 @@
+package gomu
+
+import "testing"
+
+func TestValidateDirective(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		wantErr bool
+	}{
+		{"retract version", Options{Action: "retract", Retract: "v1.0.1", Rationale: "Broken build"}, false},
+		{"retract range", Options{Action: "retract", Retract: "[v1.0.0,v1.0.5]", Rationale: "Leaks connections"}, false},
+		{"retract without version", Options{Action: "retract", Rationale: "Broken build"}, true},
+		{"retract invalid version", Options{Action: "retract", Retract: "v1.0", Rationale: "Broken build"}, true},
+		{"retract invalid range", Options{Action: "retract", Retract: "v1.0.0,v1.0.5", Rationale: "Broken build"}, true},
+		{"retract without rationale", Options{Action: "retract", Retract: "v1.0.1"}, true},
+		{"deprecate", Options{Action: "deprecate", Rationale: "Use github.com/x/z"}, false},
+		{"deprecate without rationale", Options{Action: "deprecate", Rationale: " "}, true},
+		{"other actions", Options{Action: "sync"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.options.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
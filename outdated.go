@@ -0,0 +1,171 @@
+package gomu
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/hatchify/mod-utils/com"
+	"github.com/hatchify/mod-utils/sort"
+)
+
+// OutdatedDep represents a requirement of a lib that is behind the latest known version
+type OutdatedDep struct {
+	Lib      string `json:"lib"`
+	Module   string `json:"module"`
+	Current  string `json:"current"`
+	Local    string `json:"local,omitempty"` // Latest tag of a sibling lib
+	Proxy    string `json:"proxy,omitempty"` // Latest version in the module proxy
+	Distance string `json:"distance"`
+	Indirect bool   `json:"indirect"`
+}
+
+// siblingVersions returns the semver tags of each sorted lib by module path
+func siblingVersions(fileHead *sort.FileNode) (versions map[string][]com.Version) {
+	versions = make(map[string][]com.Version)
+	for itr := fileHead; itr != nil; itr = itr.Next {
+		tags, err := itr.File.Versions()
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+
+		modulePath := itr.File.ModulePath()
+		if len(modulePath) == 0 {
+			modulePath = itr.File.GetGoURL()
+		}
+
+		versions[modulePath] = tags
+	}
+
+	return
+}
+
+// newestUpgrade returns the highest of versions above current. Pre-releases are skipped unless current is a pre-release, as with go list -m -u
+func newestUpgrade(current com.Version, versions ...com.Version) (newest com.Version, ok bool) {
+	newest = current
+	for _, version := range versions {
+		if version.IsPrerelease() && !current.IsPrerelease() {
+			continue
+		}
+
+		if version.Compare(newest) > 0 {
+			newest = version
+			ok = true
+		}
+	}
+
+	return
+}
+
+// outdated adds each requirement of a lib that is behind a sibling lib's latest tag, or the proxy's latest version
+func (mu *MU) outdated(lib Library, fileHead *sort.FileNode) {
+	if mu.siblingVersions == nil {
+		mu.siblingVersions = siblingVersions(fileHead)
+		mu.proxyVersions = make(map[string]string)
+	}
+
+	lib.File.Output("Checking requirements...")
+
+	count := 0
+	for _, requirement := range lib.File.Requirements() {
+		current, ok := com.ParseVersion(requirement.Version)
+		if !ok {
+			continue
+		}
+
+		dep := OutdatedDep{
+			Lib:      lib.File.GetGoURL(),
+			Module:   requirement.Path,
+			Current:  requirement.Version,
+			Indirect: requirement.Indirect,
+		}
+
+		latest := current
+		if local, ok := newestUpgrade(current, mu.siblingVersions[requirement.Path]...); ok {
+			dep.Local = local.String()
+			latest = local
+		}
+
+		if mu.Options.Proxy {
+			if proxy, ok := com.ParseVersion(mu.proxyVersion(lib, requirement.Path)); ok {
+				if proxy, ok = newestUpgrade(current, proxy); ok {
+					dep.Proxy = proxy.String()
+					if proxy.Compare(latest) > 0 {
+						latest = proxy
+					}
+				}
+			}
+		}
+
+		if latest.Compare(current) <= 0 {
+			continue
+		}
+
+		dep.Distance = current.Distance(latest)
+		mu.Stats.Outdated = append(mu.Stats.Outdated, dep)
+		count++
+	}
+
+	if count == 0 {
+		lib.File.Output("All requirements up to date!")
+	} else {
+		lib.File.Output(fmt.Sprintf("%d requirement(s) outdated", count))
+	}
+}
+
+// proxyVersion returns the latest version of a module in the module proxy, cached per run
+func (mu *MU) proxyVersion(lib Library, modulePath string) string {
+	if version, ok := mu.proxyVersions[modulePath]; ok {
+		return version
+	}
+
+	version, err := lib.File.ResolveVersion(modulePath, "latest")
+	if err != nil {
+		lib.File.Debug("Unable to resolve latest " + modulePath + ": " + err.Error())
+	}
+
+	mu.proxyVersions[modulePath] = version
+	return version
+}
+
+// formatOutdated renders outdated requirements as a table or json
+func formatOutdated(deps []OutdatedDep, outputFormat string) string {
+	if outputFormat == "json" {
+		if deps == nil {
+			deps = []OutdatedDep{}
+		}
+
+		data, err := json.MarshalIndent(deps, "", "\t")
+		if err != nil {
+			return err.Error() + "\n"
+		}
+
+		return string(data) + "\n"
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "LIB\tMODULE\tCURRENT\tLOCAL\tPROXY\tDISTANCE\tTYPE")
+	for _, dep := range deps {
+		requirementType := "direct"
+		if dep.Indirect {
+			requirementType = "indirect"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", dep.Lib, dep.Module, dep.Current,
+			orDash(dep.Local), orDash(dep.Proxy), dep.Distance, requirementType)
+	}
+
+	w.Flush()
+	return buf.String()
+}
+
+func orDash(value string) string {
+	if len(value) == 0 {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,79 @@
+package gomu
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+// testVersions parses tags as versions
+func testVersions(t *testing.T, tags ...string) (versions []com.Version) {
+	t.Helper()
+
+	for _, tag := range tags {
+		version, ok := com.ParseVersion(tag)
+		if !ok {
+			t.Fatalf("invalid version %s", tag)
+		}
+
+		versions = append(versions, version)
+	}
+
+	return
+}
+
+func TestNewestUpgrade(t *testing.T) {
+	tests := []struct {
+		current  string
+		versions []string
+		want     string
+	}{
+		{"v1.2.0", []string{"v1.1.0", "v1.2.0"}, ""},
+		{"v1.2.0", []string{"v1.2.1", "v1.3.0", "v1.2.9"}, "v1.3.0"},
+		{"v1.2.0", []string{"v1.3.0-rc.1"}, ""},
+		{"v1.2.0", []string{"v1.2.1", "v1.3.0-rc.1"}, "v1.2.1"},
+		{"v1.3.0-rc.1", []string{"v1.3.0-rc.2", "v1.2.5"}, "v1.3.0-rc.2"},
+		{"v1.3.0-rc.1", []string{"v1.3.0-rc.2", "v1.3.0"}, "v1.3.0"},
+		{"v1.2.0", nil, ""},
+	}
+
+	for _, test := range tests {
+		current := testVersions(t, test.current)[0]
+		newest, ok := newestUpgrade(current, testVersions(t, test.versions...)...)
+		if ok != (len(test.want) > 0) || (ok && newest.String() != test.want) {
+			t.Errorf("newestUpgrade(%s, %v) = %s, %v, want %q", test.current, test.versions, newest, ok, test.want)
+		}
+	}
+}
+
+func TestOutdated(t *testing.T) {
+	lib := testPolicyLib(t, "module github.com/org/lib\n\ngo 1.17\n\nrequire (\n"+
+		"\tgithub.com/org/a v1.2.0\n"+
+		"\tgithub.com/org/b v1.2.0\n"+
+		"\tgithub.com/org/c v0.3.0-rc.1\n"+
+		"\tgithub.com/org/d v1.0.0 // indirect\n"+
+		"\tgithub.com/x/external v1.0.0\n"+
+		")\n")
+
+	var mu MU
+	mu.siblingVersions = map[string][]com.Version{
+		"github.com/org/a": testVersions(t, "v1.2.0", "v1.3.0-rc.1"),
+		"github.com/org/b": testVersions(t, "v1.2.0", "v1.2.1", "v1.3.0-rc.1"),
+		"github.com/org/c": testVersions(t, "v0.3.0-rc.2"),
+		"github.com/org/d": testVersions(t, "v2.0.0"),
+	}
+
+	mu.outdated(*lib, nil)
+
+	url := lib.File.GetGoURL()
+	want := []OutdatedDep{
+		{Lib: url, Module: "github.com/org/b", Current: "v1.2.0", Local: "v1.2.1", Distance: "patch"},
+		{Lib: url, Module: "github.com/org/c", Current: "v0.3.0-rc.1", Local: "v0.3.0-rc.2", Distance: "prerelease"},
+		{Lib: url, Module: "github.com/org/d", Current: "v1.0.0", Local: "v2.0.0", Distance: "major", Indirect: true},
+	}
+
+	if !reflect.DeepEqual(mu.Stats.Outdated, want) {
+		t.Errorf("outdated() = %+v, want %+v", mu.Stats.Outdated, want)
+	}
+}
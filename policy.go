@@ -0,0 +1,145 @@
+package gomu
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+// PolicyFile is the repo-local file describing a lib's sync policy
+const PolicyFile = ".gomu.json"
+
+// Policy represents how gomu may update a lib's dependencies
+type Policy struct {
+	Exclude   bool                 `json:"exclude"`   // Never sync this lib
+	SkipMajor bool                 `json:"skipMajor"` // Never upgrade dependencies to a new major version
+	Deps      map[string]DepPolicy `json:"deps"`      // Per dependency policy by module path
+}
+
+// DepPolicy represents how gomu may update a single dependency
+type DepPolicy struct {
+	Exclude   bool   `json:"exclude"`   // Never upgrade this dependency
+	Max       string `json:"max"`       // Highest allowed version (v1.4 allows any v1.4.x)
+	SkipMajor bool   `json:"skipMajor"` // Never upgrade to a new major version
+}
+
+// Policy reads the lib's sync policy. Libs without a policy file allow all upgrades
+func (lib *Library) Policy() (policy Policy) {
+	data, err := ioutil.ReadFile(path.Join(lib.File.Path, PolicyFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			lib.File.Output("Unable to read " + PolicyFile + " :(")
+		}
+
+		return
+	}
+
+	if err = json.Unmarshal(data, &policy); err != nil {
+		lib.File.Output("Ignoring invalid " + PolicyFile + " :( " + err.Error())
+		return Policy{}
+	}
+
+	return
+}
+
+// dep returns the policy for a dependency, including lib-wide settings
+func (policy Policy) dep(url string) (dep DepPolicy) {
+	dep = policy.Deps[url]
+	dep.SkipMajor = dep.SkipMajor || policy.SkipMajor
+	return
+}
+
+// skipReason returns why upgrading a dependency to version is not allowed, or empty if it is allowed
+func (lib *Library) skipReason(policy Policy, url, version string) string {
+	dep := policy.dep(url)
+	if dep.Exclude {
+		return "excluded by " + PolicyFile
+	}
+
+	if len(version) == 0 {
+		return ""
+	}
+
+	if lib.File.ExcludesVersion(url, version) {
+		return "excluded by go.mod"
+	}
+
+	return lib.policyReason(dep, url, lib.File.RequiredVersion(url), version)
+}
+
+// policyReason returns why moving a dependency from the current to the next version is not allowed by its policy, or empty if it is allowed
+func (lib *Library) policyReason(dep DepPolicy, url, current, version string) string {
+	next, ok := com.ParseVersion(version)
+	if !ok {
+		return ""
+	}
+
+	if len(dep.Max) > 0 {
+		if allowed, valid := next.AtMost(dep.Max); !valid {
+			lib.File.Output("Ignoring invalid max version " + dep.Max + " for " + url)
+		} else if !allowed {
+			return "above max " + dep.Max
+		}
+	}
+
+	if dep.SkipMajor {
+		if current, ok := com.ParseVersion(current); ok && next.Major > current.Major {
+			return "major upgrade from " + current.String()
+		}
+	}
+
+	return ""
+}
+
+// skipDep records a dependency upgrade that was not allowed by policy
+func (lib *Library) skipDep(url, version, reason string) {
+	upgrade := url
+	if len(version) > 0 {
+		upgrade += "@" + version
+	}
+
+	lib.File.Output("Skipped " + upgrade + " (" + reason + ")")
+	lib.skippedDeps = append(lib.skippedDeps, upgrade+" ("+reason+")")
+}
+
+// requiredVersions returns the versions required in go.mod by module path
+func (lib *Library) requiredVersions() (versions map[string]string) {
+	versions = make(map[string]string)
+	for _, requirement := range lib.File.Requirements() {
+		versions[requirement.Path] = requirement.Version
+	}
+
+	return
+}
+
+// checkTidiedDeps returns an error if go mod tidy moved any dependency to a version not allowed by the lib's policy
+func (lib *Library) checkTidiedDeps(policy Policy, previous map[string]string) (err error) {
+	for _, requirement := range lib.File.Requirements() {
+		current := previous[requirement.Path]
+		if current == requirement.Version {
+			continue
+		}
+
+		dep := policy.dep(requirement.Path)
+		reason := lib.policyReason(dep, requirement.Path, current, requirement.Version)
+		if dep.Exclude && len(current) > 0 {
+			reason = "excluded by " + PolicyFile
+		}
+
+		if len(reason) == 0 {
+			continue
+		}
+
+		// Another requirement raised the dependency during minimal version selection
+		upgrade := requirement.Path + "@" + requirement.Version
+		lib.File.Output("Tidy raised " + upgrade + " (" + reason + ") :(")
+		lib.skippedDeps = append(lib.skippedDeps, upgrade+" ("+reason+" after tidy)")
+		err = fmt.Errorf("tidy raised %s: %s", upgrade, reason)
+	}
+
+	return
+}
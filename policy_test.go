@@ -0,0 +1,109 @@
+package gomu
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+const testPolicyModFile = `module github.com/org/lib
+
+go 1.17
+
+require (
+	github.com/x/a v1.2.3
+	github.com/x/b v0.1.0
+	github.com/x/c v1.0.0
+)
+
+exclude github.com/x/a v1.4.0
+`
+
+// testPolicyLib returns a lib with go.mod content modFile
+func testPolicyLib(t *testing.T, modFile string) *Library {
+	dir := t.TempDir()
+	testFiles(t, dir, map[string]string{"go.mod": modFile})
+	return &Library{File: &com.FileWrapper{Path: dir}}
+}
+
+func TestSkipReason(t *testing.T) {
+	policy := Policy{
+		SkipMajor: true,
+		Deps: map[string]DepPolicy{
+			"github.com/x/a": {Max: "v1.5"},
+			"github.com/x/b": {Exclude: true},
+			"github.com/x/e": {Max: "1.5"},
+		},
+	}
+
+	tests := []struct {
+		url     string
+		version string
+		want    string
+	}{
+		{"github.com/x/a", "", ""},
+		{"github.com/x/a", "v1.5.2", ""},
+		{"github.com/x/a", "v1.4.0", "excluded by go.mod"},
+		{"github.com/x/a", "v1.6.0", "above max v1.5"},
+		{"github.com/x/b", "", "excluded by " + PolicyFile},
+		{"github.com/x/b", "v0.2.0", "excluded by " + PolicyFile},
+		{"github.com/x/c", "v1.9.0", ""},
+		{"github.com/x/c", "v2.0.0+incompatible", "major upgrade from v1.0.0"},
+		{"github.com/x/d", "v3.0.0", ""},
+		{"github.com/x/e", "v9.0.0", ""},
+		{"github.com/x/c", "master", ""},
+	}
+
+	lib := testPolicyLib(t, testPolicyModFile)
+	for _, test := range tests {
+		t.Run(test.url+"@"+test.version, func(t *testing.T) {
+			if got := lib.skipReason(policy, test.url, test.version); got != test.want {
+				t.Errorf("skipReason() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckTidiedDeps(t *testing.T) {
+	policy := Policy{
+		Deps: map[string]DepPolicy{
+			"github.com/x/a": {Max: "v1.2"},
+			"github.com/x/b": {Exclude: true},
+			"github.com/x/c": {SkipMajor: true},
+		},
+	}
+
+	previous := map[string]string{
+		"github.com/x/a": "v1.2.3",
+		"github.com/x/b": "v0.1.0",
+		"github.com/x/c": "v1.0.0",
+	}
+
+	tests := []struct {
+		name    string
+		require string
+		want    []string
+	}{
+		{"unchanged", "github.com/x/a v1.2.3\n\tgithub.com/x/b v0.1.0\n\tgithub.com/x/c v1.0.0", nil},
+		{"allowed", "github.com/x/a v1.2.9\n\tgithub.com/x/c v1.3.0\n\tgithub.com/x/d v0.1.0", nil},
+		{"above max", "github.com/x/a v1.3.0", []string{"github.com/x/a@v1.3.0 (above max v1.2 after tidy)"}},
+		{"excluded", "github.com/x/b v0.2.0", []string{"github.com/x/b@v0.2.0 (excluded by " + PolicyFile + " after tidy)"}},
+		{"major", "github.com/x/a v1.2.4\n\tgithub.com/x/c v2.0.0+incompatible", []string{"github.com/x/c@v2.0.0+incompatible (major upgrade from v1.0.0 after tidy)"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lib := testPolicyLib(t, "module github.com/org/lib\n\ngo 1.17\n\nrequire (\n\t"+test.require+"\n)\n")
+
+			err := lib.checkTidiedDeps(policy, previous)
+			if (err != nil) != (len(test.want) > 0) {
+				t.Errorf("checkTidiedDeps() = %v, want error %v", err, len(test.want) > 0)
+			}
+
+			if strings.Join(lib.skippedDeps, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("skippedDeps = %v, want %v", lib.skippedDeps, test.want)
+			}
+		})
+	}
+}
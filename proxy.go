@@ -0,0 +1,274 @@
+package gomu
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hatchify/mod-utils/com"
+	"github.com/hatchify/mod-utils/sort"
+
+	"golang.org/x/mod/module"
+)
+
+// DefaultProxyAddress is the address serve-proxy listens on when not set
+const DefaultProxyAddress = "localhost:8421"
+
+// ModuleProxy serves the GOPROXY protocol (list, info, mod, zip and latest) from local git repos
+type ModuleProxy struct {
+	paths   []string
+	modules map[string]*com.FileWrapper
+}
+
+// proxyInfo is the .info response of the GOPROXY protocol
+type proxyInfo struct {
+	Version string
+	Time    time.Time
+}
+
+// NewModuleProxy returns a proxy serving the module of each sorted lib
+func NewModuleProxy(fileHead *sort.FileNode) *ModuleProxy {
+	proxy := ModuleProxy{modules: make(map[string]*com.FileWrapper)}
+	for itr := fileHead; itr != nil; itr = itr.Next {
+		modulePath := itr.File.ModulePath()
+		if len(modulePath) == 0 {
+			// Only modules can be served
+			continue
+		}
+
+		if subdir, err := itr.File.RepoSubdir(); err != nil || len(subdir) > 0 {
+			// Tags, go.mod and zips are read from the repo root
+			itr.File.Output("Not serving " + modulePath + " :( only modules at a repo root can be served")
+			continue
+		}
+
+		// Cache path before serving concurrent requests
+		itr.File.AbsPath()
+
+		proxy.paths = append(proxy.paths, modulePath)
+		proxy.modules[modulePath] = itr.File
+	}
+
+	return &proxy
+}
+
+// Modules returns the module paths served by the proxy
+func (proxy *ModuleProxy) Modules() []string {
+	return proxy.paths
+}
+
+// ServeHTTP handles $module/@v/list, $module/@v/$version.info|.mod|.zip and $module/@latest requests
+func (proxy *ModuleProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	escaped := strings.TrimPrefix(r.URL.Path, "/")
+
+	var request string
+	if index := strings.LastIndex(escaped, "/@v/"); index >= 0 {
+		escaped, request = escaped[:index], escaped[index+len("/@v/"):]
+	} else if strings.HasSuffix(escaped, "/@latest") {
+		escaped, request = strings.TrimSuffix(escaped, "/@latest"), "@latest"
+	} else {
+		http.NotFound(w, r)
+		return
+	}
+
+	modulePath, err := module.UnescapePath(escaped)
+	if err != nil {
+		http.Error(w, "not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	lib, ok := proxy.modules[modulePath]
+	if !ok {
+		// Let the go command fall back to the next proxy
+		http.Error(w, "not found: "+modulePath+" is not a local module", http.StatusNotFound)
+		return
+	}
+
+	lib.Debug("Serving " + r.URL.Path)
+
+	switch {
+	case request == "list":
+		proxy.serveList(w, lib, modulePath)
+	case request == "@latest":
+		proxy.serveLatest(w, lib, modulePath)
+	case strings.HasSuffix(request, ".info"), strings.HasSuffix(request, ".mod"), strings.HasSuffix(request, ".zip"):
+		index := strings.LastIndex(request, ".")
+		version, err := module.UnescapeVersion(request[:index])
+		if err != nil {
+			http.Error(w, "not found: "+err.Error(), http.StatusNotFound)
+			return
+		}
+
+		proxy.serveVersion(w, lib, modulePath, version, request[index+1:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveList writes the version tags of a module, one per line
+func (proxy *ModuleProxy) serveList(w http.ResponseWriter, lib *com.FileWrapper, modulePath string) {
+	versions, err := lib.ModuleVersions(modulePath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var list bytes.Buffer
+	for _, version := range versions {
+		list.WriteString(version.String() + "\n")
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	w.Write(list.Bytes())
+}
+
+// serveLatest writes the info of the latest version tag, or a pseudo-version of HEAD for untagged modules
+func (proxy *ModuleProxy) serveLatest(w http.ResponseWriter, lib *com.FileWrapper, modulePath string) {
+	query := "HEAD"
+	if latest, found := lib.LatestModuleVersion(modulePath); found {
+		query = latest.String()
+	}
+
+	proxy.serveVersion(w, lib, modulePath, query, "info")
+}
+
+// serveVersion writes the info, go.mod or zip file of a version, pseudo-version or git revision
+func (proxy *ModuleProxy) serveVersion(w http.ResponseWriter, lib *com.FileWrapper, modulePath, query, ext string) {
+	version, hash, err := lib.ResolveRevision(modulePath, query)
+	if err != nil {
+		http.Error(w, "not found: "+modulePath+"@"+query+": unknown revision", http.StatusNotFound)
+		return
+	}
+
+	if ext == "info" {
+		// Queries resolve to canonical versions
+		var info proxyInfo
+		info.Version = version
+		if _, info.Time, err = lib.RevisionInfo(hash); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(info)
+		return
+	}
+
+	if version != query {
+		http.Error(w, "not found: "+modulePath+"@"+query+": not a canonical version", http.StatusNotFound)
+		return
+	}
+
+	// Build content before writing headers so errors can be reported
+	var content []byte
+	contentType := "text/plain; charset=UTF-8"
+	if ext == "zip" {
+		var zipFile bytes.Buffer
+		err = lib.ModuleZip(&zipFile, modulePath, version, hash)
+		content, contentType = zipFile.Bytes(), "application/zip"
+	} else {
+		content, err = lib.ModFileAt(modulePath, hash)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(content)
+}
+
+// proxyURL returns the GOPROXY url of a listener address
+func proxyURL(address net.Addr) string {
+	return "http://" + address.String()
+}
+
+// serveProxy serves the sorted libs as a module proxy until interrupted
+func (mu *MU) serveProxy(fileHead *sort.FileNode) {
+	proxy := NewModuleProxy(fileHead)
+	modules := proxy.Modules()
+	if len(modules) == 0 {
+		com.Println("No modules found. Nothing to serve :(")
+		return
+	}
+
+	listener, err := net.Listen("tcp", mu.Options.proxyAddress())
+	if err != nil {
+		com.Println("Failed to start module proxy :( " + err.Error())
+		mu.Errors = append(mu.Errors, err)
+		return
+	}
+
+	for index, modulePath := range modules {
+		com.Println(strconv.Itoa(index+1) + ") " + modulePath)
+	}
+
+	com.Println("\nServing", len(modules), "module(s) at "+proxyURL(listener.Addr())+". Resolve them with:")
+	com.Println("  GOPROXY=" + proxyURL(listener.Addr()) + ",https://proxy.golang.org,direct GONOSUMDB=" + strings.Join(modules, ","))
+
+	if err = http.Serve(listener, proxy); err != nil {
+		com.Println("Module proxy stopped :( " + err.Error())
+		mu.Errors = append(mu.Errors, err)
+	}
+}
+
+// useLocalProxy serves the sorted libs on a free port and resolves them through it in spawned go commands
+func (mu *MU) useLocalProxy(fileHead *sort.FileNode) bool {
+	proxy := NewModuleProxy(fileHead)
+	modules := proxy.Modules()
+	if len(modules) == 0 {
+		return true
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		com.Println("Failed to start local module proxy :( " + err.Error())
+		mu.Errors = append(mu.Errors, err)
+		return false
+	}
+
+	go http.Serve(listener, proxy)
+
+	output, err := fileHead.File.CmdOutput("go", "env", "GOPROXY", "GONOPROXY", "GONOSUMDB")
+	if err != nil {
+		com.Println("Failed to read go env :( " + err.Error())
+		mu.Errors = append(mu.Errors, err)
+		return false
+	}
+
+	values := strings.Split(output+"\n\n", "\n")
+	goProxy, noProxy, noSumDB := strings.TrimSpace(values[0]), strings.TrimSpace(values[1]), strings.TrimSpace(values[2])
+
+	env := com.GetGoEnv()
+	switch {
+	case goProxy == "off":
+		env.Proxy = proxyURL(listener.Addr())
+	case len(noProxy) > 0:
+		// Private modules would bypass the local proxy, fetch them (and public modules) directly instead
+		env.Proxy = proxyURL(listener.Addr()) + ",direct"
+		env.NoProxy = "none"
+	default:
+		env.Proxy = proxyURL(listener.Addr()) + "," + goProxy
+	}
+
+	if len(noSumDB) > 0 {
+		noSumDB += ","
+	}
+
+	// Local versions are not in the checksum database
+	env.NoSumDB = noSumDB + strings.Join(modules, ",")
+	com.SetGoEnv(env)
+
+	com.Println("\nResolving", len(modules), "local module(s) through "+proxyURL(listener.Addr()))
+	return true
+}
@@ -0,0 +1,153 @@
+package gomu
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/hatchify/mod-utils/com"
+	"github.com/hatchify/mod-utils/sort"
+)
+
+const testModFile = "module github.com/X/lib\n\ngo 1.17\n"
+
+// testRepo returns a git repo with a module at the root and one in a subdirectory
+func testRepo(t *testing.T) (dir string) {
+	t.Helper()
+	testGitIdentity(t)
+
+	dir = t.TempDir()
+	testFiles(t, dir, map[string]string{
+		"go.mod":        testModFile,
+		"lib.go":        "package lib\n",
+		"sub/go.mod":    "module github.com/X/lib/sub\n\ngo 1.17\n",
+		"sub/sub.go":    "package sub\n",
+		"internal/x.go": "package internal\n",
+	})
+
+	testGit(t, dir, "init", "-q")
+	testGit(t, dir, "add", "-A")
+	testGit(t, dir, "commit", "-q", "-m", "Initial commit")
+	testGit(t, dir, "tag", "v1.0.0")
+	testGit(t, dir, "tag", "v1.1.0-rc.1")
+	return
+}
+
+func TestNewModuleProxy(t *testing.T) {
+	dir := testRepo(t)
+
+	var fileHead *sort.FileNode
+	for _, libPath := range []string{dir, path.Join(dir, "sub"), path.Join(dir, "internal")} {
+		node := &sort.FileNode{File: &com.FileWrapper{Path: libPath}}
+		node.InsertInto(&fileHead)
+	}
+
+	// Modules in subdirectories are tagged and zipped from the repo root
+	proxy := NewModuleProxy(fileHead)
+	if modules := proxy.Modules(); len(modules) != 1 || modules[0] != "github.com/X/lib" {
+		t.Errorf("Modules() = %v, want only the root module", modules)
+	}
+}
+
+func TestModuleProxyServeHTTP(t *testing.T) {
+	dir := testRepo(t)
+
+	// Commit after the latest tag
+	if err := ioutil.WriteFile(path.Join(dir, "lib.go"), []byte("package lib\n\nconst Lib = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testGit(t, dir, "commit", "-q", "-a", "-m", "Add Lib")
+	head := testGit(t, dir, "rev-parse", "HEAD")
+	pseudo := "v1.1.0-rc.1.0.20210601120000-" + head[:12]
+
+	server := httptest.NewServer(NewModuleProxy(&sort.FileNode{File: &com.FileWrapper{Path: dir}}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{"list", "GET", "/github.com/!x/lib/@v/list", 200, "v1.0.0\nv1.1.0-rc.1\n"},
+		{"latest", "GET", "/github.com/!x/lib/@latest", 200, `{"Version":"v1.0.0","Time":"2021-06-01T12:00:00Z"}`},
+		{"info", "GET", "/github.com/!x/lib/@v/v1.0.0.info", 200, `{"Version":"v1.0.0","Time":"2021-06-01T12:00:00Z"}`},
+		{"commit info", "GET", "/github.com/!x/lib/@v/" + head[:12] + ".info", 200, `{"Version":"` + pseudo + `","Time":"2021-06-01T12:00:00Z"}`},
+		{"mod", "GET", "/github.com/!x/lib/@v/v1.0.0.mod", 200, testModFile},
+		{"pseudo mod", "GET", "/github.com/!x/lib/@v/" + pseudo + ".mod", 200, testModFile},
+		{"non-canonical mod", "GET", "/github.com/!x/lib/@v/" + head[:12] + ".mod", 404, ""},
+		{"unknown version", "GET", "/github.com/!x/lib/@v/v9.9.9.info", 404, ""},
+		{"unknown module", "GET", "/github.com/!x/other/@v/list", 404, ""},
+		{"unescaped path", "GET", "/github.com/X/lib/@v/list", 404, ""},
+		{"unknown request", "GET", "/github.com/!x/lib/@v/v1.0.0.txt", 404, ""},
+		{"not a proxy path", "GET", "/github.com/!x/lib", 404, ""},
+		{"method", "POST", "/github.com/!x/lib/@v/list", 405, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, _ := http.NewRequest(test.method, server.URL+test.path, nil)
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+
+			body, _ := ioutil.ReadAll(response.Body)
+			if response.StatusCode != test.status {
+				t.Fatalf("status = %d, want %d: %s", response.StatusCode, test.status, body)
+			}
+
+			if len(test.body) > 0 && strings.TrimSpace(string(body)) != strings.TrimSpace(test.body) {
+				t.Errorf("body = %s, want %s", body, test.body)
+			}
+		})
+	}
+
+	t.Run("zip", func(t *testing.T) {
+		response, err := http.Get(server.URL + "/github.com/!x/lib/@v/v1.0.0.zip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+
+		body, _ := ioutil.ReadAll(response.Body)
+		if response.StatusCode != 200 {
+			t.Fatalf("status = %d: %s", response.StatusCode, body)
+		}
+
+		reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, file := range reader.File {
+			names = append(names, file.Name)
+		}
+
+		// Nested modules are excluded, and files are read from the tagged commit
+		want := []string{"github.com/X/lib@v1.0.0/go.mod", "github.com/X/lib@v1.0.0/internal/x.go", "github.com/X/lib@v1.0.0/lib.go"}
+		if strings.Join(names, ",") != strings.Join(want, ",") {
+			t.Errorf("zip files = %v, want %v", names, want)
+		}
+
+		for _, file := range reader.File {
+			if strings.HasSuffix(file.Name, "/lib.go") {
+				content, _ := file.Open()
+				data, _ := ioutil.ReadAll(content)
+				content.Close()
+
+				if string(data) != "package lib\n" {
+					t.Errorf("lib.go = %q, want the tagged content", data)
+				}
+			}
+		}
+	})
+}
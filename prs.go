@@ -0,0 +1,127 @@
+package gomu
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+// PRStatus represents the status of an open pull request for a lib
+type PRStatus struct {
+	Lib    string `json:"lib"`
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Branch string `json:"branch"`
+
+	State     string `json:"state"`
+	Mergeable string `json:"mergeable"`
+	CI        string `json:"ci"`
+	Review    string `json:"review"`
+
+	Created time.Time `json:"created"`
+}
+
+// isGomuPR returns true if a pull request was opened by gomu, or from the provided branch
+func isGomuPR(title, head, branch string) bool {
+	return strings.HasPrefix(title, "gomu:") || (len(branch) > 0 && head == branch)
+}
+
+// prStatuses returns the status of every open gomu pull request for a lib
+func (mu *MU) prStatuses(lib Library) (statuses []PRStatus, err error) {
+	if err = lib.File.CheckForge(com.FeaturePullRequests); err != nil {
+		lib.File.Output("Skipping :( " + err.Error())
+		return
+	}
+
+	lib.File.Output("Checking pull requests...")
+
+	prs, err := lib.File.ListPullRequests("open")
+	if err != nil {
+		lib.File.Output("Unable to list pull requests :( " + err.Error())
+		return
+	}
+
+	for _, listed := range prs {
+		if !isGomuPR(listed.Title, listed.Head.Ref, mu.Options.Branch) {
+			continue
+		}
+
+		status := PRStatus{
+			Lib:       lib.File.GetGoURL(),
+			Number:    listed.Number,
+			URL:       listed.URL,
+			Branch:    listed.Head.Ref,
+			State:     listed.State,
+			Mergeable: "unknown",
+			CI:        "none",
+			Created:   listed.CreatedAt,
+		}
+
+		if listed.Draft {
+			status.State = "draft"
+		}
+
+		// Mergeability is only returned for single pull requests
+		if pr, err := lib.File.GetPullRequest(listed.Number); err == nil {
+			if pr.Mergeable != nil {
+				if *pr.Mergeable {
+					status.Mergeable = "yes"
+				} else {
+					status.Mergeable = "conflicts"
+				}
+			}
+
+			if pr.MergeableState == "blocked" || pr.MergeableState == "behind" {
+				status.Mergeable = pr.MergeableState
+			}
+		}
+
+		if ci, err := lib.File.CheckStatus(listed.Head.SHA); err == nil && len(ci) > 0 {
+			status.CI = ci
+		}
+
+		if review, err := lib.File.ReviewStatus(listed.Number); err == nil {
+			status.Review = review
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	if len(statuses) == 0 {
+		lib.File.Output("No open pull requests.")
+	}
+
+	return
+}
+
+// formatAge returns a short human readable duration (e.g. 3d, 5h, 12m)
+func formatAge(age time.Duration) string {
+	switch {
+	case age >= 24*time.Hour:
+		return strconv.Itoa(int(age/(24*time.Hour))) + "d"
+	case age >= time.Hour:
+		return strconv.Itoa(int(age/time.Hour)) + "h"
+	default:
+		return strconv.Itoa(int(age/time.Minute)) + "m"
+	}
+}
+
+// formatPRStatuses renders pull request statuses as a table
+func formatPRStatuses(statuses []PRStatus) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "LIB\tPR\tSTATE\tMERGEABLE\tCI\tREVIEW\tAGE\tURL")
+	for _, status := range statuses {
+		fmt.Fprintf(w, "%s\t#%d\t%s\t%s\t%s\t%s\t%s\t%s\n", status.Lib, status.Number, status.State,
+			status.Mergeable, status.CI, status.Review, formatAge(time.Since(status.Created)), status.URL)
+	}
+
+	w.Flush()
+	return buf.String()
+}
@@ -0,0 +1,48 @@
+package gomu
+
+import (
+	"strconv"
+)
+
+// GetPseudoVersion returns the version tag of HEAD, or a pseudo-version of HEAD when untagged
+func (lib *Library) GetPseudoVersion() (version string) {
+	modulePath := lib.File.ModulePath()
+	if len(modulePath) == 0 {
+		modulePath = lib.File.GetGoURL()
+	}
+
+	version, _, err := lib.File.ResolveRevision(modulePath, "HEAD")
+	if err != nil {
+		lib.File.Output("Unable to determine pseudo-version :(")
+		return ""
+	}
+
+	return
+}
+
+// pseudoVersion sets the version of an updated lib to its pushed commit, so dependants can sync before anything is tagged
+func (mu *MU) pseudoVersion(lib Library) {
+	if len(lib.File.Version) > 0 || !(lib.File.Updated || lib.File.Committed) {
+		// Unchanged libs keep their latest tag
+		return
+	}
+
+	if !mu.Options.LocalProxy && !lib.File.IsPushed() {
+		// Dependants could not resolve the commit
+		lib.File.Output("Changes not pushed. Unable to sync dependants to a pseudo-version :(")
+		return
+	}
+
+	if lib.File.Version = lib.GetPseudoVersion(); len(lib.File.Version) == 0 {
+		return
+	}
+
+	lib.File.Output("Syncing dependants @ " + lib.File.Version)
+	mu.Stats.PseudoCount++
+	mu.Stats.PseudoOutput += strconv.Itoa(mu.Stats.PseudoCount) + ") " + lib.File.Path + " " + lib.File.Version + "\n"
+}
+
+// allowPseudo returns whether untagged deps may be set to pseudo-versions, and whether unpushed commits resolve through the local proxy
+func (mu *MU) allowPseudo() (pseudo, localProxy bool) {
+	return mu.Options.Pseudo || mu.Options.LocalProxy, mu.Options.LocalProxy
+}
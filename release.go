@@ -0,0 +1,28 @@
+package gomu
+
+import (
+	"strconv"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+// release creates a forge release for a pushed tag
+func (mu *MU) release(lib Library, tag, notes string) {
+	lib.File.Output("Creating release " + tag + "...")
+
+	version, _ := com.ParseVersion(tag)
+	releaseURL, err := lib.File.CreateRelease(com.Release{
+		Tag:        tag,
+		Notes:      notes,
+		Prerelease: version.IsPrerelease(),
+	})
+
+	if err != nil {
+		lib.File.Output("Failed to create release :( " + err.Error())
+		return
+	}
+
+	lib.File.Output("Release Created!")
+	mu.Stats.ReleaseCount++
+	mu.Stats.ReleaseOutput += strconv.Itoa(mu.Stats.ReleaseCount) + ") " + lib.File.Path + " " + tag + " " + releaseURL + "\n"
+}
@@ -0,0 +1,59 @@
+package gomu
+
+import (
+	"strconv"
+	"strings"
+)
+
+// isSkippedDependant returns true for dependants of retracted or deprecated libs when they shouldn't be synced
+func (mu *MU) isSkippedDependant(lib Library) bool {
+	switch mu.Options.Action {
+	case "retract", "deprecate":
+		return !mu.targets[lib.File.Path] && !mu.Options.Dependants
+	}
+
+	return false
+}
+
+// updateModDirectives adds a retract directive or deprecation comment to a target lib's go.mod and commits it before mod files are reset
+func (mu *MU) updateModDirectives(lib Library) {
+	if !mu.targets[lib.File.Path] {
+		// Dependants only update their requires
+		return
+	}
+
+	var err error
+	var title string
+	if mu.Options.Action == "retract" {
+		err = lib.File.Retract(mu.Options.Retract, mu.Options.Rationale)
+		title = "gomu: Retract " + mu.Options.Retract
+	} else {
+		err = lib.File.Deprecate(mu.Options.Rationale)
+		title = "gomu: Deprecate module"
+	}
+
+	if err != nil {
+		lib.File.Output("Failed to update mod file :( " + err.Error())
+		mu.Errors = append(mu.Errors, err)
+		return
+	}
+
+	message := title
+	if len(mu.Options.Rationale) > 0 {
+		message += "\n\n" + mu.Options.Rationale
+	}
+
+	if err = lib.File.Add("go.mod"); err == nil {
+		err = lib.File.Commit(message)
+	}
+
+	if err != nil {
+		lib.File.Output("Failed to commit mod file :(")
+		return
+	}
+
+	lib.File.Output(strings.TrimPrefix(title, "gomu: ") + "!")
+	lib.File.Committed = true
+	mu.Stats.DirectiveCount++
+	mu.Stats.DirectiveOutput += strconv.Itoa(mu.Stats.DirectiveCount) + ") " + lib.File.Path + "\n"
+}
@@ -0,0 +1,177 @@
+package gomu
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+func (mu *MU) secret(lib Library) (err error) {
+	if err = lib.File.CheckForge(com.FeatureSecrets); err != nil {
+		lib.File.Output("Skipping :( " + err.Error())
+		return
+	}
+
+	scope := mu.Options.secretScope()
+	if scope.Target == com.SecretOrg && mu.Options.SecretAction != "diff" {
+		// Org secrets are handled once per org after collecting libs
+		mu.orgSecretLibs = append(mu.orgSecretLibs, lib)
+		lib.File.Output("Queued for " + mu.Options.SecretAction + " organization secret")
+		return
+	}
+
+	switch mu.Options.SecretAction {
+	case "list":
+		return mu.listSecrets(lib, scope)
+	case "delete":
+		return mu.deleteSecret(lib, scope)
+	case "diff":
+		return mu.diffSecret(lib, scope)
+	default:
+		return mu.addSecret(lib, scope)
+	}
+}
+
+// readSecret reads the secret value from the source file
+func (mu *MU) readSecret() (secret string, err error) {
+	body, err := ioutil.ReadFile(mu.Options.SourcePath)
+	if err != nil {
+		return
+	}
+
+	return string(body), nil
+}
+
+func (mu *MU) addSecret(lib Library, scope com.SecretScope) (err error) {
+	secret, err := mu.readSecret()
+	if err != nil {
+		lib.File.Output("Unable to open secret file :(")
+		return
+	}
+
+	// Set secret on repo
+	if err = lib.File.AddSecretTo(scope, mu.Options.secretName(), secret); err != nil {
+		lib.File.Output("Unable to add secret :( " + err.Error())
+		return
+	}
+
+	mu.secretUpdated(lib, mu.secretLabel(lib, scope))
+	return
+}
+
+func (mu *MU) listSecrets(lib Library, scope com.SecretScope) (err error) {
+	secrets, err := lib.File.ListSecretsIn(scope)
+	if err != nil {
+		lib.File.Output("Unable to list secrets :( " + err.Error())
+		return
+	}
+
+	names := make([]string, len(secrets))
+	for i := range secrets {
+		names[i] = secrets[i].Name
+	}
+
+	label := mu.secretLabel(lib, scope)
+	lib.File.Output(strconv.Itoa(len(names)) + " secret(s) " + strings.Join(names, ", "))
+	mu.Stats.SecretsOutput += label + ": " + strings.Join(names, ", ") + "\n"
+	return
+}
+
+func (mu *MU) deleteSecret(lib Library, scope com.SecretScope) (err error) {
+	if err = lib.File.DeleteSecretFrom(scope, mu.Options.secretName()); err != nil {
+		lib.File.Output("Unable to delete secret :( " + err.Error())
+		return
+	}
+
+	mu.secretUpdated(lib, mu.secretLabel(lib, scope))
+	return
+}
+
+// diffSecret reports libs missing the named secret
+func (mu *MU) diffSecret(lib Library, scope com.SecretScope) (err error) {
+	name := mu.Options.secretName()
+
+	found, err := lib.File.HasSecret(scope, name)
+	if err != nil {
+		lib.File.Output("Unable to check secrets :( " + err.Error())
+		return
+	}
+
+	if found {
+		lib.File.Output("Has secret " + name)
+		return
+	}
+
+	lib.File.Output("Missing secret " + name + "!")
+	mu.Stats.MissingCount++
+	mu.Stats.MissingOutput += strconv.Itoa(mu.Stats.MissingCount) + ") " + lib.File.GetGoURL() + "\n"
+	return
+}
+
+// orgSecrets sets, lists or deletes org secrets once per org for the collected libs
+func (mu *MU) orgSecrets() {
+	var orgs []string
+	orgLibs := make(map[string][]Library)
+	for _, lib := range mu.orgSecretLibs {
+		host, org := lib.File.Org()
+		key := host + "/" + org
+		if _, ok := orgLibs[key]; !ok {
+			orgs = append(orgs, key)
+		}
+
+		orgLibs[key] = append(orgLibs[key], lib)
+	}
+
+	for _, org := range orgs {
+		libs := orgLibs[org]
+		lib := libs[0]
+		scope := mu.Options.secretScope()
+
+		// Separate output
+		com.Println("")
+		com.Println(org)
+
+		switch mu.Options.SecretAction {
+		case "list":
+			mu.listSecrets(lib, scope)
+		case "delete":
+			mu.deleteSecret(lib, scope)
+		default:
+			// Grant access to repositories gomu is syncing, in addition to those already selected
+			for _, selected := range libs {
+				id, err := selected.File.RepoID()
+				if err != nil {
+					selected.File.Output("Unable to get repository id :( " + err.Error())
+					continue
+				}
+
+				scope.RepositoryIDs = append(scope.RepositoryIDs, id)
+			}
+
+			if len(scope.RepositoryIDs) == 0 {
+				lib.File.Output("No repositories to grant secret access")
+				continue
+			}
+
+			mu.addSecret(lib, scope)
+		}
+	}
+}
+
+func (mu *MU) secretUpdated(lib Library, label string) {
+	lib.File.Updated = true
+	mu.Stats.UpdateCount++
+	mu.Stats.UpdatedOutput += strconv.Itoa(mu.Stats.UpdateCount) + ") " + label + "\n"
+}
+
+// secretLabel returns the lib path, or the org name for org secrets
+func (mu *MU) secretLabel(lib Library, scope com.SecretScope) string {
+	if scope.Target == com.SecretOrg {
+		host, org := lib.File.Org()
+		return host + "/" + org
+	}
+
+	return lib.File.Path
+}
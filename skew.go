@@ -0,0 +1,139 @@
+package gomu
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+// ModuleSkew represents every version of a module required across libs
+type ModuleSkew struct {
+	Module   string         `json:"module"`
+	Versions []VersionUsage `json:"versions"`
+	Skewed   bool           `json:"skewed"` // More distinct versions than the threshold
+}
+
+// VersionUsage represents the libs requiring a module version
+type VersionUsage struct {
+	Version string   `json:"version"`
+	Libs    []string `json:"libs"`
+}
+
+// collectRequirements records the module versions required by a lib
+func (mu *MU) collectRequirements(lib Library) {
+	if mu.requirements == nil {
+		mu.requirements = make(map[string]map[string][]string)
+	}
+
+	requirements := lib.File.Requirements()
+	for _, requirement := range requirements {
+		versions, ok := mu.requirements[requirement.Path]
+		if !ok {
+			versions = make(map[string][]string)
+			mu.requirements[requirement.Path] = versions
+		}
+
+		versions[requirement.Version] = append(versions[requirement.Version], lib.File.GetGoURL())
+	}
+
+	lib.File.Output("Found " + strconv.Itoa(len(requirements)) + " requirement(s)")
+}
+
+// skewReport returns each module required by more than one lib, most skewed first
+func (mu *MU) skewReport() (report []ModuleSkew) {
+	for module, versions := range mu.requirements {
+		skew := ModuleSkew{Module: module, Skewed: len(versions) > mu.Options.skewThreshold()}
+
+		libCount := 0
+		for version, libs := range versions {
+			sort.Strings(libs)
+			skew.Versions = append(skew.Versions, VersionUsage{Version: version, Libs: libs})
+			libCount += len(libs)
+		}
+
+		if libCount < 2 {
+			// A module required by a single lib can't be skewed
+			continue
+		}
+
+		sort.Slice(skew.Versions, func(i, j int) bool {
+			a, aOK := com.ParseVersion(skew.Versions[i].Version)
+			b, bOK := com.ParseVersion(skew.Versions[j].Version)
+			if aOK && bOK {
+				return a.Compare(b) > 0
+			}
+
+			return skew.Versions[i].Version > skew.Versions[j].Version
+		})
+
+		report = append(report, skew)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if len(report[i].Versions) != len(report[j].Versions) {
+			return len(report[i].Versions) > len(report[j].Versions)
+		}
+
+		return report[i].Module < report[j].Module
+	})
+
+	return
+}
+
+// formatSkew renders module skew as a table or json
+func formatSkew(report []ModuleSkew, outputFormat string) string {
+	if outputFormat == "json" {
+		if report == nil {
+			report = []ModuleSkew{}
+		}
+
+		data, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			return err.Error() + "\n"
+		}
+
+		return string(data) + "\n"
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "\tMODULE\tVERSION\tLIBS")
+	for _, skew := range report {
+		marker := ""
+		if skew.Skewed {
+			marker = "!"
+		}
+
+		for i, usage := range skew.Versions {
+			module := ""
+			if i == 0 {
+				module = skew.Module
+			} else {
+				marker = ""
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, module, usage.Version, strings.Join(usage.Libs, ", "))
+		}
+	}
+
+	w.Flush()
+	return buf.String()
+}
+
+// skewedCount returns the number of modules over the skew threshold
+func skewedCount(report []ModuleSkew) (count int) {
+	for _, skew := range report {
+		if skew.Skewed {
+			count++
+		}
+	}
+
+	return
+}
@@ -0,0 +1,68 @@
+package gomu
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSkewReport(t *testing.T) {
+	requirements := map[string]map[string][]string{
+		"github.com/x/a": {
+			"v1.2.0":      {"lib/c", "lib/a"},
+			"v1.10.0":     {"lib/b"},
+			"v1.3.0-rc.1": {"lib/d"},
+		},
+		"github.com/x/b": {"v0.1.0": {"lib/a", "lib/b"}},
+		"github.com/x/c": {"v2.0.0": {"lib/a"}},
+		"github.com/x/d": {
+			"v0.0.0-20210601120000-abcdefabcdef": {"lib/a"},
+			"v0.1.0":                             {"lib/b"},
+		},
+	}
+
+	skewA := ModuleSkew{Module: "github.com/x/a", Versions: []VersionUsage{
+		{"v1.10.0", []string{"lib/b"}},
+		{"v1.3.0-rc.1", []string{"lib/d"}},
+		{"v1.2.0", []string{"lib/a", "lib/c"}},
+	}}
+	skewD := ModuleSkew{Module: "github.com/x/d", Versions: []VersionUsage{
+		{"v0.1.0", []string{"lib/b"}},
+		{"v0.0.0-20210601120000-abcdefabcdef", []string{"lib/a"}},
+	}}
+	skewB := ModuleSkew{Module: "github.com/x/b", Versions: []VersionUsage{
+		{"v0.1.0", []string{"lib/a", "lib/b"}},
+	}}
+
+	tests := []struct {
+		name      string
+		threshold int
+		skewed    []bool
+	}{
+		{"default threshold", 0, []bool{true, true, false}},
+		{"threshold 2", 2, []bool{true, false, false}},
+		{"threshold 3", 3, []bool{false, false, false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu MU
+			mu.Options.SkewThreshold = test.threshold
+			mu.requirements = requirements
+
+			// Most distinct versions first, modules required by a single lib are left out
+			want := []ModuleSkew{skewA, skewD, skewB}
+			for i := range want {
+				want[i].Skewed = test.skewed[i]
+			}
+
+			report := mu.skewReport()
+			if !reflect.DeepEqual(report, want) {
+				t.Errorf("skewReport() = %+v, want %+v", report, want)
+			}
+
+			if count := skewedCount(report); count != skewedCount(want) {
+				t.Errorf("skewedCount() = %d, want %d", count, skewedCount(want))
+			}
+		})
+	}
+}
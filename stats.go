@@ -1,6 +1,9 @@
 package gomu
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // ActionStats contain stats related to the current action
 type ActionStats struct {
@@ -10,15 +13,56 @@ type ActionStats struct {
 	UpdateCount   int
 	UpdatedOutput string
 
+	SkippedCount  int
+	SkippedOutput string
+
+	UpgradeCount   int
+	UpgradedOutput string
+
 	TagCount     int
 	TaggedOutput string
 
+	PseudoCount  int
+	PseudoOutput string
+
+	BreakingSkippedCount  int
+	BreakingSkippedOutput string
+	BreakingForcedCount   int
+	BreakingForcedOutput  string
+
+	MajorRequiredCount  int
+	MajorRequiredOutput string
+
+	ReleaseCount  int
+	ReleaseOutput string
+
 	CommitCount    int
 	DeployedOutput string
 
 	PRCount  int
 	PROutput string
 
+	PRStatuses []PRStatus
+
+	Outdated []OutdatedDep
+	Skew     []ModuleSkew
+
+	MajorCount  int
+	MajorOutput string
+
+	GoVersionCount  int
+	GoVersionOutput string
+	GoSkippedCount  int
+	GoSkippedOutput string
+
+	DirectiveCount  int
+	DirectiveOutput string
+
+	SecretsOutput string
+
+	MissingCount  int
+	MissingOutput string
+
 	CreatedCount  int
 	CreatedOutput string
 
@@ -55,6 +99,56 @@ func (stats ActionStats) Format() (output string) {
 			output += "Tests failed in " + strconv.Itoa(stats.TestFailedCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s) :(\n"
 			output += stats.TestFailedOutput
 		}
+	case "prs":
+		if len(stats.PRStatuses) == 0 {
+			output += "No open pull requests in " + strconv.Itoa(stats.DepCount) + " lib(s).\n"
+		} else {
+			output += "Open pull requests in " + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += formatPRStatuses(stats.PRStatuses)
+		}
+	case "skew":
+		if stats.Options.OutputFormat == "json" {
+			// Only print parsable output
+			return formatSkew(stats.Skew, "json")
+		}
+
+		if len(stats.Skew) == 0 {
+			output += "No shared requirements in " + strconv.Itoa(stats.DepCount) + " lib(s).\n"
+		} else {
+			output += "Shared requirements in " + strconv.Itoa(stats.DepCount) + " lib(s), " + strconv.Itoa(skewedCount(stats.Skew)) + " module(s) with more than " + strconv.Itoa(stats.Options.skewThreshold()) + " version(s):\n"
+			output += formatSkew(stats.Skew, "table")
+		}
+	case "outdated":
+		if stats.Options.OutputFormat == "json" {
+			// Only print parsable output
+			return formatOutdated(stats.Outdated, "json")
+		}
+
+		if len(stats.Outdated) == 0 {
+			output += "All requirements up to date in " + strconv.Itoa(stats.DepCount) + " lib(s)!\n"
+		} else {
+			output += "Outdated requirements in " + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += formatOutdated(stats.Outdated, "table")
+		}
+	case "secret":
+		switch stats.Options.SecretAction {
+		case "list":
+			output += "Secrets in " + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.SecretsOutput
+		case "diff":
+			if stats.MissingCount == 0 {
+				output += "Secret " + stats.Options.secretName() + " available in all " + strconv.Itoa(stats.DepCount) + " lib(s)!\n"
+			} else {
+				output += "Secret " + stats.Options.secretName() + " missing in " + strconv.Itoa(stats.MissingCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+				output += stats.MissingOutput
+			}
+		case "delete":
+			output += "Deleted secret " + stats.Options.secretName() + " in " + strconv.Itoa(stats.UpdateCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.UpdatedOutput
+		default:
+			output += "Set secret " + stats.Options.secretName() + " in " + strconv.Itoa(stats.UpdateCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.UpdatedOutput
+		}
 	case "replace":
 		output += "Replaced local dependencies in " + strconv.Itoa(stats.UpdateCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
 		output += stats.UpdatedOutput
@@ -62,6 +156,42 @@ func (stats ActionStats) Format() (output string) {
 		output += "Reset mod files in " + strconv.Itoa(stats.DepCount) + " lib(s)\n"
 		// TODO: Count libs with changes here?
 		output += "Warning: Local changes will no longer apply\n" //in " + strconv.Itoa(stats.DepCount) + " lib(s)\n"
+	case "major":
+		output += "Migrated major versions in " + strconv.Itoa(stats.MajorCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+		output += stats.MajorOutput
+		if stats.UpdateCount > 0 {
+			output += "\nUpdated mod files in <" + branch + "> for " + strconv.Itoa(stats.UpdateCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.UpdatedOutput
+		}
+	case "go-version":
+		output += "Updated go directive to " + strings.TrimPrefix(stats.Options.GoVersion, "go") + " in " + strconv.Itoa(stats.GoVersionCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+		output += stats.GoVersionOutput
+		if stats.GoSkippedCount > 0 {
+			output += "\nUnable to update go directive in " + strconv.Itoa(stats.GoSkippedCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.GoSkippedOutput
+		}
+		if stats.UpdateCount > 0 {
+			output += "\nUpdated mod files in <" + branch + "> for " + strconv.Itoa(stats.UpdateCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.UpdatedOutput
+		}
+	case "retract", "deprecate":
+		if stats.Options.Action == "retract" {
+			output += "Retracted " + stats.Options.Retract + " in " + strconv.Itoa(stats.DirectiveCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+		} else {
+			output += "Deprecated " + strconv.Itoa(stats.DirectiveCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+		}
+		output += stats.DirectiveOutput
+		if stats.UpdateCount > 0 {
+			output += "\nUpdated mod files in <" + branch + "> for " + strconv.Itoa(stats.UpdateCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.UpdatedOutput
+		}
+	case "upgrade":
+		if stats.UpgradeCount == 0 {
+			output += "All " + strconv.Itoa(stats.DepCount) + " lib(s) already up to date!\n"
+		} else {
+			output += "Upgraded modules in <" + branch + "> for " + strconv.Itoa(stats.UpgradeCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.UpgradedOutput
+		}
 	case "sync":
 		// Print update status
 		if stats.UpdateCount == 0 {
@@ -72,6 +202,11 @@ func (stats ActionStats) Format() (output string) {
 		}
 	}
 
+	if stats.SkippedCount > 0 {
+		output += "\nSkipped " + strconv.Itoa(stats.SkippedCount) + " dependency upgrade(s) by policy:\n"
+		output += stats.SkippedOutput
+	}
+
 	if stats.Options.Tag {
 		// Print tag status
 		output += "\n"
@@ -85,6 +220,42 @@ func (stats ActionStats) Format() (output string) {
 			}
 			output += stats.TaggedOutput
 		}
+
+		if stats.BreakingForcedCount > 0 {
+			output += "Forced tag bump for incompatible api changes in " + strconv.Itoa(stats.BreakingForcedCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.BreakingForcedOutput
+		}
+
+		if stats.BreakingSkippedCount > 0 {
+			output += "Skipped tag for incompatible api changes in " + strconv.Itoa(stats.BreakingSkippedCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.BreakingSkippedOutput
+		}
+
+		if stats.MajorRequiredCount > 0 {
+			output += "Skipped tag for breaking changes that need a new major version in " + strconv.Itoa(stats.MajorRequiredCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.MajorRequiredOutput
+		}
+
+		if stats.Options.Release {
+			output += "\n"
+			if stats.ReleaseCount == 0 {
+				output += "No releases created in " + strconv.Itoa(stats.DepCount) + " lib(s).\n"
+			} else {
+				output += "Created releases for " + strconv.Itoa(stats.ReleaseCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+				output += stats.ReleaseOutput
+			}
+		}
+	}
+
+	if stats.Options.Pseudo && !stats.Options.Tag {
+		// Print pseudo-version status
+		output += "\n"
+		if stats.PseudoCount == 0 {
+			output += "No pseudo-versions synced in " + strconv.Itoa(stats.DepCount) + " lib(s).\n"
+		} else {
+			output += "Synced dependants to pseudo-versions for " + strconv.Itoa(stats.PseudoCount) + "/" + strconv.Itoa(stats.DepCount) + " lib(s):\n"
+			output += stats.PseudoOutput
+		}
 	}
 
 	if stats.Options.Commit {
@@ -1,92 +1,150 @@
 package gomu
 
 import (
-	"strings"
+	"fmt"
+	"strconv"
+
+	"github.com/hatchify/mod-utils/com"
 )
 
-// TagLib updates the lib to the provided tag, or increments if git-tagger is able to
+// TagLib updates the lib to the provided tag, or increments the patch version of the latest tag
 func (lib *Library) TagLib(tag string) (newTag string) {
 	if len(tag) == 0 {
-		lib.File.Output("Updating tag...")
+		return lib.BumpTag("patch", "")
+	}
+
+	lib.File.Output("Setting tag...")
+
+	if newTag = lib.pushTag(tag, tag); len(newTag) > 0 {
+		lib.File.Output("Set Tag - " + newTag)
+	}
 
-		// Use git-tagger to increment
-		if lib.File.RunCmd("git-tagger") != nil {
-			lib.File.Output("Unable to increment tag.")
-			return
-		}
+	return
+}
+
+// BumpTag increments the lib's latest tag by level (major, minor, patch or pre), with an optional pre-release identifier
+func (lib *Library) BumpTag(level, identifier string) (newTag string) {
+	lib.File.Output("Updating tag...")
 
-		newTag = lib.GetLatestTag()
+	tag, err := lib.NextTag(level, identifier)
+	if err != nil {
+		lib.File.Output("Unable to increment tag :( " + err.Error())
+		return
+	}
+
+	if newTag = lib.pushTag(tag, tag); len(newTag) > 0 {
 		lib.File.Output("Incremented tag - " + newTag)
+	}
 
-	} else {
-		lib.File.Output("Setting tag...")
+	return
+}
 
-		// Set tag manually
-		if lib.File.RunCmd("git", "tag", tag) != nil {
-			lib.File.Output("Unable to set tag.")
-			return
-		}
+// NextTag returns the lib's latest tag incremented by level (major, minor, patch or pre).
+// Returns an error if the lib has no tag, or the next version can't be tagged for its module path
+func (lib *Library) NextTag(level, identifier string) (tag string, err error) {
+	latest, found := lib.File.LatestVersion()
+	if !found {
+		err = fmt.Errorf("no tag set")
+		return
+	}
 
-		// Push new tag
-		if lib.File.RunCmd("git", "push", "--tag") != nil {
-			lib.File.Output("Unable to push tag.")
-			return
-		}
+	modulePath := lib.File.ModulePath()
+	if len(modulePath) == 0 {
+		modulePath = lib.File.GetGoURL()
+	}
+
+	next, err := com.NextVersion(latest, modulePath, level, identifier)
+	return next.String(), err
+}
 
-		newTag = tag
+// AnnotateTag sets the provided tag with a message as its annotation
+func (lib *Library) AnnotateTag(tag, message string) (newTag string) {
+	lib.File.Output("Setting tag...")
+
+	if newTag = lib.pushTag(tag, message); len(newTag) > 0 {
 		lib.File.Output("Set Tag - " + newTag)
 	}
 
 	return
 }
 
-// ShouldTag returns true if not a plugin and has a tag that is out of date
-func (lib *Library) ShouldTag() (shouldTag bool) {
-	// Check if tag is up to date
-	stdout, err := lib.File.CmdOutput("git-tagger", "--action=get")
-	if err != nil {
+// pushTag creates an annotated tag and pushes only that tag
+func (lib *Library) pushTag(tag, message string) (newTag string) {
+	if err := lib.File.CreateTag(tag, message); err != nil {
+		lib.File.Output("Unable to set tag.")
+		return
+	}
+
+	if err := lib.File.PushTag(tag); err != nil {
+		lib.File.Output("Unable to push tag.")
+		return
+	}
+
+	return tag
+}
+
+// ShouldTag returns the version bump implied by conventional commits since the latest tag, or empty if the tag is up to date
+func (lib *Library) ShouldTag() (bump string) {
+	latest, found := lib.File.LatestVersion()
+	if !found {
 		// No tag set. skip tag
 		lib.File.Output("No tag set. Skipping tag.")
 		return
 	}
-	tag := strings.TrimSpace(string(stdout))
+	// Build metadata is part of the git ref
+	tag := latest.Tag()
 
-	stdout, err = lib.File.CmdOutput("git", "rev-list", "-n", "1", tag)
+	commits, err := lib.File.CommitsSince(tag)
 	if err != nil {
 		// No tag set. skip tag
 		lib.File.Output("No revision history. Skipping tag.")
 		return
 	}
-	tagCommit := string(stdout)
 
-	stdout, err = lib.File.CmdOutput("git", "rev-parse", "HEAD")
-	if err != nil {
-		// No tag set. skip tag
-		lib.File.Output("No revision head. Skipping tag.")
+	if len(commits) == 0 {
+		lib.File.Output("Tag up to date @ " + tag + "!")
 		return
 	}
-	headCommit := string(stdout)
 
-	if tagCommit != headCommit {
-		// Tag out of date
-		lib.File.Output("Tag outdated...")
-		return true
+	bump = com.InferBump(latest, commits)
+	lib.File.Output("Tag outdated by " + strconv.Itoa(len(commits)) + " commit(s). Suggested " + bump + " bump...")
+	return
+}
+
+// APIBump returns the bump level required by exported api changes between the latest tag and HEAD.
+// Incompatible changes only require a minor bump before v1.0.0
+func (lib *Library) APIBump() (required string, diff com.APIDiff, err error) {
+	latest, found := lib.File.LatestVersion()
+	if !found {
+		err = fmt.Errorf("no tag set")
+		return
+	}
+
+	var old, current com.ExportedAPI
+	if old, err = lib.File.ExportedAPIAt(latest.Tag()); err != nil {
+		return
+	}
+
+	if current, err = lib.File.ExportedAPIAt("HEAD"); err != nil {
+		return
+	}
+
+	diff = com.CompareAPI(old, current)
+	if required = diff.Bump(); required == "major" && latest.Major == 0 {
+		required = "minor"
 	}
 
-	lib.File.Output("Tag up to date @ " + tag + "!")
 	return
 }
 
-// GetLatestTag returns the latest tag for a given dir
-// TODO: create GetLatestTag for this functinoality
-// TODO: use git-tagger --action=current to return current tag rather than latest tag
+// GetLatestTag returns the highest semantic version tag reachable from HEAD
 func (lib *Library) GetLatestTag() (currentTag string) {
-	output, err := lib.File.CmdOutput("git-tagger", "--action=get")
-	if err != nil {
+	latest, found := lib.File.LatestVersion()
+	if !found {
 		// No tag set. skip tag
 		lib.File.Output("Unable to fetch tag.")
 		return
 	}
 
-	return output
+	return latest.String()
 }
@@ -0,0 +1,101 @@
+package gomu
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hatchify/mod-utils/com"
+	"github.com/hatchify/mod-utils/sort"
+)
+
+// moduleUpgrade represents an external module version to set in every lib that requires it
+type moduleUpgrade struct {
+	Path    string
+	Version string
+}
+
+// String returns the upgrade as module@version
+func (upgrade moduleUpgrade) String() string {
+	return upgrade.Path + "@" + upgrade.Version
+}
+
+// parseUpgrades parses module@version upgrades, resolving queries (latest, v1, etc) to versions
+func (mu *MU) parseUpgrades(fileHead *sort.FileNode) (ok bool) {
+	if fileHead == nil {
+		return
+	}
+
+	for _, arg := range mu.Options.Upgrades {
+		comps := strings.SplitN(arg, "@", 2)
+		upgrade := moduleUpgrade{Path: comps[0], Version: "latest"}
+		if len(comps) == 2 && len(comps[1]) > 0 {
+			upgrade.Version = comps[1]
+		}
+
+		if _, isVersion := com.ParseVersion(upgrade.Version); !isVersion {
+			version, err := fileHead.File.ResolveVersion(upgrade.Path, upgrade.Version)
+			if err != nil {
+				com.Println("Unable to resolve " + arg + " :( " + err.Error())
+				mu.Errors = append(mu.Errors, err)
+				return
+			}
+
+			com.Println("Resolved " + arg + " to " + version)
+			upgrade.Version = version
+		}
+
+		mu.upgrades = append(mu.upgrades, upgrade)
+	}
+
+	return len(mu.upgrades) > 0
+}
+
+// upgradePaths returns the module paths of all upgrades, used to find libs requiring them
+func (mu *MU) upgradePaths() (paths sort.StringArray) {
+	for _, arg := range mu.Options.Upgrades {
+		paths = append(paths, strings.SplitN(arg, "@", 2)[0])
+	}
+
+	return
+}
+
+// ModSetUpgrades sets external module versions required by the lib, unless older or not allowed by the lib's policy
+func (lib *Library) ModSetUpgrades() {
+	policy := lib.Policy()
+	for _, upgrade := range lib.upgrades {
+		required := lib.File.RequiredVersion(upgrade.Path)
+		if len(required) == 0 {
+			// Only set modules the lib already requires
+			continue
+		}
+
+		current, currentOK := com.ParseVersion(required)
+		next, nextOK := com.ParseVersion(upgrade.Version)
+		if currentOK && nextOK && current.Compare(next) >= 0 {
+			lib.File.Output("Already has " + upgrade.Path + " @ " + required)
+			continue
+		}
+
+		if reason := lib.skipReason(policy, upgrade.Path, upgrade.Version); len(reason) > 0 {
+			lib.skipDep(upgrade.Path, upgrade.Version, reason)
+			continue
+		}
+
+		if lib.File.RunCmd("go", "get", "-d", upgrade.String()) == nil {
+			lib.File.Output("Upgraded " + upgrade.Path + " @ " + upgrade.Version)
+			lib.upgraded = append(lib.upgraded, upgrade.String())
+		} else {
+			lib.File.Output("Error: Failed to get " + upgrade.String())
+		}
+	}
+}
+
+// recordUpgrades adds a lib's upgraded modules to stats
+func (mu *MU) recordUpgrades(lib Library) {
+	if len(lib.upgraded) == 0 {
+		return
+	}
+
+	mu.Stats.UpgradeCount++
+	mu.Stats.UpgradedOutput += strconv.Itoa(mu.Stats.UpgradeCount) + ") " + lib.File.Path + " - " + strings.Join(lib.upgraded, ", ") + "\n"
+}
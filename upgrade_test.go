@@ -0,0 +1,89 @@
+package gomu
+
+import (
+	"archive/zip"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+// testFileProxy writes versions of modulePath to a file:// GOPROXY and uses it for spawned go commands
+func testFileProxy(t *testing.T, modulePath string, versions ...string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	versionDir := path.Join(dir, modulePath, "@v")
+	goMod := "module " + modulePath + "\n\ngo 1.17\n"
+	testFiles(t, versionDir, map[string]string{"list": strings.Join(versions, "\n") + "\n"})
+
+	for _, version := range versions {
+		testFiles(t, versionDir, map[string]string{
+			version + ".info": `{"Version":"` + version + `","Time":"2021-06-01T12:00:00Z"}`,
+			version + ".mod":  goMod,
+		})
+
+		file, err := os.Create(path.Join(versionDir, version+".zip"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		archive := zip.NewWriter(file)
+		for name, content := range map[string]string{"go.mod": goMod, "a.go": "package a\n"} {
+			w, _ := archive.Create(modulePath + "@" + version + "/" + name)
+			w.Write([]byte(content))
+		}
+
+		archive.Close()
+		file.Close()
+	}
+
+	t.Setenv("GOPROXY", "file://"+dir)
+	t.Setenv("GOFLAGS", "-mod=mod -modcacherw")
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOTOOLCHAIN", "local")
+}
+
+func TestModSetUpgrades(t *testing.T) {
+	testFileProxy(t, "github.com/x/a", "v1.2.0", "v1.3.0")
+
+	tests := []struct {
+		name         string
+		upgrade      moduleUpgrade
+		policy       string
+		wantRequired string
+		wantUpgraded string
+		wantSkipped  string
+	}{
+		{"upgrades", moduleUpgrade{"github.com/x/a", "v1.3.0"}, "", "v1.3.0", "github.com/x/a@v1.3.0", ""},
+		{"not required", moduleUpgrade{"github.com/x/b", "v1.3.0"}, "", "v1.2.0", "", ""},
+		{"already newer", moduleUpgrade{"github.com/x/a", "v1.1.0"}, "", "v1.2.0", "", ""},
+		{"not allowed by policy", moduleUpgrade{"github.com/x/a", "v1.3.0"}, `{"deps":{"github.com/x/a":{"max":"v1.2"}}}`, "v1.2.0", "", "github.com/x/a@v1.3.0 (above max v1.2)"},
+		{"unavailable", moduleUpgrade{"github.com/x/a", "v1.9.0"}, "", "v1.2.0", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lib := testPolicyLib(t, "module github.com/org/lib\n\ngo 1.17\n\nrequire github.com/x/a v1.2.0\n")
+			if len(test.policy) > 0 {
+				testFiles(t, lib.File.Path, map[string]string{PolicyFile: test.policy})
+			}
+
+			lib.upgrades = []moduleUpgrade{test.upgrade}
+			lib.ModSetUpgrades()
+
+			if got := lib.File.RequiredVersion("github.com/x/a"); got != test.wantRequired {
+				t.Errorf("required version = %s, want %s", got, test.wantRequired)
+			}
+
+			if got := strings.Join(lib.upgraded, ","); got != test.wantUpgraded {
+				t.Errorf("upgraded = %s, want %s", got, test.wantUpgraded)
+			}
+
+			if got := strings.Join(lib.skippedDeps, ","); got != test.wantSkipped {
+				t.Errorf("skippedDeps = %s, want %s", got, test.wantSkipped)
+			}
+		})
+	}
+}
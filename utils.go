@@ -3,9 +3,7 @@ package gomu
 import (
 	"bufio"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path"
 	"runtime"
 	"strconv"
 	"strings"
@@ -90,18 +88,141 @@ func cleanupStash(libs sort.StringArray) {
 	waiter.Wait()
 }
 
-func (mu *MU) sync(lib Library, commitTitle, commitMessage string) {
+// gitHosts returns each distinct git host with a known api among the sorted libs
+func gitHosts(fileHead *sort.FileNode) (hosts []string) {
+	seen := make(map[string]bool)
+	for itr := fileHead; itr != nil; itr = itr.Next {
+		host := strings.Split(itr.File.GetGoURL(), "/")[0]
+		if seen[host] || !com.HasAPI(host) {
+			continue
+		}
+
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+
+	return
+}
+
+// credentialHosts returns the git hosts whose forge supports any of the features
+func credentialHosts(fileHead *sort.FileNode, features []string) (hosts []string) {
+	for _, host := range gitHosts(fileHead) {
+		for _, feature := range features {
+			if com.Supports(host, feature) {
+				hosts = append(hosts, host)
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// ensureCredentials loads (or prompts for) credentials for each git host used by the action before any concurrent work starts
+func (mu *MU) ensureCredentials(fileHead *sort.FileNode) (ok bool) {
+	for _, host := range credentialHosts(fileHead, mu.Options.forgeFeatures()) {
+		authObject, err := com.LoadAuthFor(host)
+		if err == nil {
+			continue
+		}
+
+		com.Println("")
+		com.Println("gomu :: I needs " + host + " credentials for Pull Requests...")
+		if authObject.Setup() != nil || len(authObject.Token) == 0 {
+			com.Println("Error saving :(")
+			mu.Errors = append(mu.Errors, fmt.Errorf("Unable to parse %s username and token", host))
+			return
+		}
+	}
+
+	return true
+}
+
+// findTargets marks the filtered libs themselves (not their dependants) as targets of the action
+func (mu *MU) findTargets(fileHead *sort.FileNode) (count int) {
+	filters := make([]*com.FileWrapper, len(mu.Options.FilterDependencies))
+	for i, filter := range mu.Options.FilterDependencies {
+		filters[i] = &com.FileWrapper{Path: strings.Split(filter, "@")[0]}
+	}
+
+	mu.targets = make(map[string]bool)
+	for itr := fileHead; itr != nil; itr = itr.Next {
+		for _, filter := range filters {
+			if strings.HasSuffix(itr.File.GetGoURL(), filter.GetGoURL()) {
+				mu.targets[itr.File.Path] = true
+				count++
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// ensureSigning checks that each lib can sign commits before any changes are made
+func (mu *MU) ensureSigning(fileHead *sort.FileNode) (ok bool) {
+	ok = true
+	for itr := fileHead; itr != nil; itr = itr.Next {
+		if err := itr.File.CheckSigning(); err != nil {
+			itr.File.Output("Unable to sign commits :( " + err.Error())
+			mu.Errors = append(mu.Errors, err)
+			ok = false
+		}
+	}
+
+	return
+}
+
+// verifyCredentials checks the credentials for each git host against the host api
+func (mu *MU) verifyCredentials(fileHead *sort.FileNode) {
+	hosts := gitHosts(fileHead)
+	if len(hosts) == 0 {
+		hosts = []string{com.DefaultHost}
+	}
+
+	for _, host := range hosts {
+		authObject, err := com.LoadAuthFor(host)
+		if err != nil {
+			com.Println(host, ":: No credentials found :(")
+			mu.Errors = append(mu.Errors, err)
+			continue
+		}
+
+		login, err := authObject.Verify()
+		if err != nil {
+			com.Println(host, ":: Credentials invalid :( "+err.Error())
+			mu.Errors = append(mu.Errors, err)
+			continue
+		}
+
+		com.Println(host, ":: Authenticated as "+login+"!")
+	}
+}
+
+func (mu *MU) sync(lib *Library, commitTitle string) {
 	// Update the dep if necessary
-	if err := lib.ModUpdate(mu.Options.Branch, commitTitle+"\n"+commitMessage); err == nil {
+	if err := lib.ModUpdate(mu.Options.Branch, commitTitle); err == nil {
 		// Dep was updated
 		lib.File.Updated = true
 		mu.Stats.UpdateCount++
 		mu.Stats.UpdatedOutput += strconv.Itoa(mu.Stats.UpdateCount) + ") " + lib.File.Path + "\n"
 	}
+
+	mu.recordUpgrades(*lib)
+
+	for _, skipped := range lib.skippedDeps {
+		mu.Stats.SkippedCount++
+		mu.Stats.SkippedOutput += strconv.Itoa(mu.Stats.SkippedCount) + ") " + lib.File.Path + " - " + skipped + "\n"
+	}
 }
 
 func (mu *MU) pullRequest(lib Library, branch, commitTitle, commitMessage string) (err error) {
 	if mu.Options.PullRequest {
+		if err = lib.File.CheckForge(com.FeaturePullRequests); err != nil {
+			lib.File.Output("Skipping PR :( " + err.Error())
+			return
+		}
+
 		if len(branch) == 0 {
 			branch, err = lib.File.CurrentBranch()
 			if err != nil {
@@ -111,12 +232,19 @@ func (mu *MU) pullRequest(lib Library, branch, commitTitle, commitMessage string
 
 		lib.File.Output("Attempting Pull Request " + branch + " to master...")
 
-		resp, err := lib.File.PullRequest(commitTitle, commitMessage, branch, "master")
+		resp, err := lib.File.PullRequest(commitTitle, commitMessage, branch, "master", mu.Options.Draft)
 		if err == nil {
 			mu.Stats.PRCount++
 			mu.Stats.PROutput += resp.URL + "\n"
 			lib.File.PROpened = true
+			lib.File.PRNumber = resp.Number
 			lib.File.Output("PR Created!")
+
+			if meta := mu.Options.PRMetadata(); !meta.IsEmpty() {
+				if err := lib.File.ApplyPRMetadata(resp, meta); err != nil {
+					lib.File.Output("Warning - " + err.Error())
+				}
+			}
 		} else {
 			if resp == nil || len(resp.Errors) == 0 {
 				lib.File.Output("Failed to create PR :( " + err.Error())
@@ -134,8 +262,74 @@ func (mu *MU) pullRequest(lib Library, branch, commitTitle, commitMessage string
 	return
 }
 
+// checkMajorPath returns bump, or empty if the next major version requires a new module path
+func (mu *MU) checkMajorPath(lib Library, bump string) string {
+	if _, err := lib.NextTag(bump, mu.Options.Prerelease); err != nil {
+		// Dependants would silently stay on the previous major version
+		lib.File.Output("Unable to tag breaking changes :( " + err.Error())
+		if len(mu.Options.Bump) == 0 {
+			lib.File.Output("Or set an explicit bump to tag anyway!")
+		}
+
+		mu.Stats.MajorRequiredCount++
+		mu.Stats.MajorRequiredOutput += strconv.Itoa(mu.Stats.MajorRequiredCount) + ") " + lib.File.Path + " " + err.Error() + "\n"
+		return ""
+	}
+
+	return bump
+}
+
+// checkAPI returns the bump required by exported api changes, or empty if the tag should be skipped
+func (mu *MU) checkAPI(lib Library, bump string) string {
+	required, diff, err := lib.APIBump()
+	if err != nil {
+		lib.File.Output("Unable to compare exported api :(")
+		return bump
+	}
+
+	level := bump
+	if level == "pre" {
+		// Pre-releases of the next patch
+		level = "patch"
+	}
+
+	if com.MaxBump(level, required) == level {
+		return bump
+	}
+
+	if !diff.Incompatible() {
+		// Additions don't break dependants
+		lib.File.Debug("Exported api additions require a " + required + " bump")
+		return required
+	}
+
+	lib.File.Output("Incompatible api changes since the latest tag:\n" + diff.Format())
+
+	switch {
+	case mu.Options.APICheck == "abort":
+		lib.File.Output("Requested " + bump + " bump is too small. Skipping tag :(")
+	case required == "major":
+		// Tagging v2+ without a /vN module path breaks dependants
+		lib.File.Output("Breaking changes need a new major version. Run the major action. Skipping tag :(")
+	default:
+		lib.File.Output("Forcing " + required + " bump!")
+		mu.Stats.BreakingForcedCount++
+		mu.Stats.BreakingForcedOutput += strconv.Itoa(mu.Stats.BreakingForcedCount) + ") " + lib.File.Path + " " + bump + " -> " + required + " bump\n"
+		return required
+	}
+
+	mu.Stats.BreakingSkippedCount++
+	mu.Stats.BreakingSkippedOutput += strconv.Itoa(mu.Stats.BreakingSkippedCount) + ") " + lib.File.Path + " requires " + required + " bump\n"
+	return ""
+}
+
 func (mu *MU) tag(lib Library) {
 	if !mu.Options.Tag {
+		if mu.Options.Pseudo {
+			// Dependants require the pushed commit instead
+			mu.pseudoVersion(lib)
+		}
+
 		// Ignore tagging entirely
 		return
 	}
@@ -145,9 +339,36 @@ func (mu *MU) tag(lib Library) {
 		return
 	}
 
-	// Tag if forced or if able to increment
-	if mu.Options.Tag && (len(mu.Options.SetVersion) > 0 || lib.ShouldTag()) {
-		newTag := lib.TagLib(mu.Options.SetVersion)
+	// Tag if forced or if commits since the last tag imply a bump
+	var bump string
+	if len(mu.Options.SetVersion) == 0 {
+		bump = lib.ShouldTag()
+		if mu.isMajorMigration(lib) {
+			// Migrated libs are tagged with their new major version
+			bump = "major"
+		} else if len(mu.Options.Bump) > 0 && len(bump) > 0 {
+			// Explicit bump level overrides inferred level
+			bump = mu.Options.Bump
+		}
+
+		if len(bump) > 0 && len(mu.Options.APICheck) > 0 {
+			bump = mu.checkAPI(lib, bump)
+		}
+
+		if bump == "major" && !mu.isMajorMigration(lib) {
+			bump = mu.checkMajorPath(lib, bump)
+		}
+	}
+
+	if len(mu.Options.SetVersion) > 0 || len(bump) > 0 {
+		var newTag string
+		if mu.Options.Changelog || mu.Options.Release {
+			newTag = mu.tagWithNotes(lib, bump)
+		} else if len(mu.Options.SetVersion) > 0 {
+			newTag = lib.TagLib(mu.Options.SetVersion)
+		} else {
+			newTag = lib.BumpTag(bump, mu.Options.Prerelease)
+		}
 
 		if len(newTag) > 0 {
 			lib.File.Version = newTag
@@ -200,15 +421,18 @@ func (mu *MU) getCommitDetails(lib Library) (commitTitle, commitMessage string)
 	}
 
 	commitTitle = "gomu: " + commitTitle
-	commitMessage = ""
-	for itr := lib.updatedDeps; itr != nil; itr = itr.Next {
-		url := itr.File.GetGoURL()
+	commitMessage = lib.commitDetails()
+	return
+}
 
-		if itr.File.Updated {
-			commitMessage += "\nUpdated " + url + "@" + itr.File.Version
-		} else {
-			commitMessage += "\nSet " + url + "@" + itr.File.Version
-		}
+// commitDetails lists the deps and upgrades set in go.mod, one per line
+func (lib *Library) commitDetails() (details string) {
+	for _, dep := range lib.setDeps {
+		details += "\n" + dep
+	}
+
+	for _, upgrade := range lib.upgraded {
+		details += "\nUpdated " + upgrade
 	}
 
 	return
@@ -250,30 +474,6 @@ func (mu *MU) replace(lib Library, fileHead *sort.FileNode) {
 	}
 }
 
-func (mu *MU) addSecret(lib Library) (err error) {
-	// Get secret name from filepath
-	_, secretName := path.Split(mu.Options.SourcePath)
-	secretFile, err := os.Open(mu.Options.SourcePath)
-	if err != nil {
-		return err
-	}
-	defer secretFile.Close()
-
-	// Read secret from file
-	var body []byte
-	if body, err = ioutil.ReadAll(secretFile); err != nil {
-		return
-	}
-
-	// Set secret with filename on repo
-	if err = lib.File.AddSecret(secretName, string(body)); err != nil {
-		lib.File.Output("Unable to add secret :(")
-		return
-	}
-
-	return
-}
-
 func (mu *MU) test(lib Library, fileHead *sort.FileNode) (err error) {
 	if lib.File.StashPop() {
 		// Local changes exist
@@ -282,10 +482,28 @@ func (mu *MU) test(lib Library, fileHead *sort.FileNode) (err error) {
 
 	// Only set updated deps
 	lib.ModAddDeps(fileHead, false)
+	lib.pseudo, lib.localProxy = mu.allowPseudo()
 
 	if lib.updatedDeps != nil {
 		lib.File.Output("Setting dep versions...")
 		lib.ModSetDeps()
+
+		if lib.File.IsVendored() {
+			// Builds would fail with inconsistent vendoring
+			if err = lib.ModTidy(); err == nil {
+				err = lib.ModVendor()
+			}
+
+			if err != nil {
+				lib.File.Output("Mod vendor failed :(")
+				lib.File.TestFailed = true
+				mu.Stats.TestFailedCount++
+				mu.Stats.TestFailedOutput += strconv.Itoa(mu.Stats.TestFailedCount) + ") " + lib.File.Path
+
+				mu.Stats.TestFailedOutput += "\n"
+				return
+			}
+		}
 	}
 
 	lib.File.Output("Building...")
@@ -343,6 +561,12 @@ func (mu *MU) reset(lib Library) {
 	// Revert any changes to mod files
 	lib.File.RunCmd("git", "checkout", mu.Options.Branch, "go.mod")
 	lib.File.RunCmd("git", "checkout", mu.Options.Branch, "go.sum")
+	if lib.File.IsVendored() {
+		lib.File.RunCmd("git", "checkout", mu.Options.Branch, "vendor")
+
+		// Remove packages vendored from local replacements
+		lib.File.RunCmd("git", "clean", "-fd", "vendor")
+	}
 
 	lib.File.Output("Reverted mod files!")
 
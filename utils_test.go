@@ -0,0 +1,101 @@
+package gomu
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/hatchify/mod-utils/com"
+)
+
+// testTaggedLib returns a lib for modulePath at $tmp/<base of modulePath> with files, committed and tagged
+func testTaggedLib(t *testing.T, modulePath string, files map[string]string, tags ...string) Library {
+	t.Helper()
+	testGitIdentity(t)
+
+	dir := path.Join(t.TempDir(), path.Base(modulePath))
+	testFiles(t, dir, map[string]string{"go.mod": "module " + modulePath + "\n\ngo 1.17\n"})
+	testFiles(t, dir, files)
+	testGit(t, dir, "init", "-q")
+	testGit(t, dir, "add", "-A")
+	testGit(t, dir, "commit", "-q", "-m", "Initial commit")
+	for _, tag := range tags {
+		testGit(t, dir, "tag", tag)
+	}
+
+	return Library{File: &com.FileWrapper{Path: dir}}
+}
+
+func TestCheckMajorPath(t *testing.T) {
+	tests := []struct {
+		name         string
+		modulePath   string
+		tag          string
+		want         string
+		wantRequired bool
+	}{
+		{"first major", "github.com/org/repo", "v0.3.1", "major", false},
+		{"migrated module path", "github.com/org/repo/v2", "v1.4.0", "major", false},
+		{"unmigrated module path", "github.com/org/repo", "v1.4.0", "", true},
+		{"next major unmigrated", "github.com/org/repo/v2", "v2.1.0", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu MU
+			lib := testTaggedLib(t, test.modulePath, nil, test.tag)
+
+			if got := mu.checkMajorPath(lib, "major"); got != test.want {
+				t.Errorf("checkMajorPath() = %q, want %q", got, test.want)
+			}
+
+			// Skipped libs are reported so the run doesn't look successful
+			if required := mu.Stats.MajorRequiredCount == 1 && strings.Contains(mu.Stats.MajorRequiredOutput, lib.File.Path); required != test.wantRequired {
+				t.Errorf("MajorRequiredOutput = %q, want lib listed %v", mu.Stats.MajorRequiredOutput, test.wantRequired)
+			}
+		})
+	}
+}
+
+func TestCheckAPI(t *testing.T) {
+	const api = "package repo\n\nfunc Start() {}\n"
+
+	tests := []struct {
+		name        string
+		tag         string
+		apiCheck    string
+		source      string
+		want        string
+		wantForced  bool
+		wantSkipped bool
+	}{
+		{"compatible", "v1.2.0", "major", api + "func Stop() {}\n", "minor", false, false},
+		{"covered by bump", "v1.2.0", "major", api, "patch", false, false},
+		{"abort", "v1.2.0", "abort", "package repo\n", "", false, true},
+		{"needs new major version", "v1.2.0", "major", "package repo\n", "", false, true},
+		{"forced before v1", "v0.2.0", "major", "package repo\n", "minor", true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lib := testTaggedLib(t, "github.com/org/repo", map[string]string{"repo.go": api}, test.tag)
+			testFiles(t, lib.File.Path, map[string]string{"repo.go": test.source})
+			testGit(t, lib.File.Path, "commit", "-q", "-am", "Change api", "--allow-empty")
+
+			var mu MU
+			mu.Options.APICheck = test.apiCheck
+			if got := mu.checkAPI(lib, "patch"); got != test.want {
+				t.Errorf("checkAPI() = %q, want %q", got, test.want)
+			}
+
+			// Forced and skipped libs are reported separately
+			if forced := mu.Stats.BreakingForcedCount == 1; forced != test.wantForced {
+				t.Errorf("BreakingForcedOutput = %q, want lib listed %v", mu.Stats.BreakingForcedOutput, test.wantForced)
+			}
+
+			if skipped := mu.Stats.BreakingSkippedCount == 1; skipped != test.wantSkipped {
+				t.Errorf("BreakingSkippedOutput = %q, want lib listed %v", mu.Stats.BreakingSkippedOutput, test.wantSkipped)
+			}
+		})
+	}
+}